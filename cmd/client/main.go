@@ -2,21 +2,49 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"time"
 
 	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/tlsconfig"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
-	conn, err := grpc.Dial(
-		"localhost:50051",
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	addr := flag.String("addr", "localhost:50051", "server address")
+	token := flag.String("token", os.Getenv("FILE_STORAGE_TOKEN"), "bearer token: a JWT or an API key")
+	useTLS := flag.Bool("tls", false, "connect over TLS; implied by the other TLS flags")
+	caFile := flag.String("ca-cert", "", "PEM file of the CAs to verify the server with instead of the system roots")
+	certFile := flag.String("cert", "", "PEM client certificate for mutual TLS")
+	keyFile := flag.String("key", "", "PEM key of the client certificate")
+	serverName := flag.String("server-name", "", "name to verify the server certificate for, if it differs from the address")
+	flag.Parse()
+
+	transportCredentials := insecure.NewCredentials()
+	if *useTLS || *caFile != "" || *certFile != "" || *keyFile != "" || *serverName != "" {
+		tlsConfig, err := tlsconfig.NewClientConfig(*caFile, *certFile, *keyFile)
+		if err != nil {
+			log.Fatalf("failed to load TLS config: %v", err)
+		}
+		tlsConfig.ServerName = *serverName
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(*token)))
+	}
+
+	conn, err := grpc.Dial(*addr, opts...)
 	if err != nil {
 		log.Fatalf("failed to connect: %v", err)
 	}
@@ -24,19 +52,24 @@ func main() {
 
 	client := proto.NewFileServiceClient(conn)
 
-	uploadedFilename := testUploadFile(client)
+	uploadedFileID := testUploadFile(client)
 	time.Sleep(1 * time.Second)
 
 	testListFiles(client)
 	time.Sleep(1 * time.Second)
 
-	testDownloadFile(client, uploadedFilename)
+	testDownloadFile(client, uploadedFileID)
+	time.Sleep(1 * time.Second)
+
+	testResumableUpload(client)
 }
 
 func testUploadFile(client proto.FileServiceClient) string {
 	fmt.Println("=== Testing UploadFile ===")
 
 	fileContent := []byte("Hello, this is a test file content!")
+	declaredSize := uint64(len(fileContent))
+	checksum := sha256.Sum256(fileContent)
 
 	stream, err := client.UploadFile(context.Background())
 	if err != nil {
@@ -46,8 +79,10 @@ func testUploadFile(client proto.FileServiceClient) string {
 	err = stream.Send(&proto.UploadFileRequest{
 		Data: &proto.UploadFileRequest_Info{
 			Info: &proto.FileInfo{
-				Filename:    "test.txt",
-				ContentType: "text/plan",
+				Filename:       "test.txt",
+				ContentType:    "text/plain",
+				DeclaredSize:   &declaredSize,
+				ExpectedSha256: hex.EncodeToString(checksum[:]),
 			},
 		},
 	})
@@ -75,16 +110,16 @@ func testUploadFile(client proto.FileServiceClient) string {
 		log.Fatalf("failed to receive response: %v", err)
 	}
 
-	fmt.Printf("Upload successful: ID=%s, Filename=%s, Size=%d\n",
-		response.GetId(), response.GetFilename(), response.GetSize())
-	return response.GetFilename()
+	fmt.Printf("Upload successful: ID=%s, Filename=%s, Size=%d, SHA256=%s\n",
+		response.GetId(), response.GetFilename(), response.GetSize(), response.GetChecksumSha256())
+	return response.GetId()
 }
 
-func testDownloadFile(client proto.FileServiceClient, filename string) {
-	fmt.Printf("\n=== Testing DownloadFile for: %s===\n", filename)
+func testDownloadFile(client proto.FileServiceClient, fileID string) {
+	fmt.Printf("\n=== Testing DownloadFile for: %s===\n", fileID)
 
 	stream, err := client.DownloadFile(context.Background(), &proto.DownloadFileRequest{
-		Filename: filename,
+		FileId: fileID,
 	})
 	if err != nil {
 		log.Printf("failed to download file: %v", err)
@@ -105,6 +140,12 @@ func testDownloadFile(client proto.FileServiceClient, filename string) {
 	}
 
 	fmt.Printf("Downloaded %d bytes: %s\n", len(fileData), string(fileData))
+
+	checksum := sha256.Sum256(fileData)
+	if expected := stream.Trailer().Get("x-checksum-sha256"); len(expected) > 0 &&
+		expected[0] != hex.EncodeToString(checksum[:]) {
+		log.Printf("checksum mismatch: expected %s, got %x", expected[0], checksum)
+	}
 }
 
 func testListFiles(client proto.FileServiceClient) {
@@ -122,10 +163,107 @@ func testListFiles(client proto.FileServiceClient) {
 		return
 	}
 
-	fmt.Printf("Found %d files\n", response.TotalCount)
+	fmt.Printf("Found %d files\n", response.GetTotalCount())
 
 	for _, file := range response.Files {
 		fmt.Printf("- %s (size: %d bytes, created: %v)\n",
 			file.Filename, file.Size, file.CreatedAt.AsTime())
 	}
 }
+
+func testResumableUpload(client proto.FileServiceClient) {
+	fmt.Println("\n=== Testing resumable upload ===")
+
+	ctx := context.Background()
+	fileContent := []byte("This file is uploaded in two separate streams.")
+	declaredSize := uint64(len(fileContent))
+
+	session, err := client.InitiateUpload(ctx, &proto.InitiateUploadRequest{
+		Info: &proto.FileInfo{
+			Filename:     "resumable.txt",
+			DeclaredSize: &declaredSize,
+		},
+	})
+	if err != nil {
+		log.Printf("failed to initiate upload: %v", err)
+		return
+	}
+
+	half := uint64(len(fileContent) / 2)
+	if err := uploadChunk(client, session.GetUploadId(), 0, fileContent[:half]); err != nil {
+		log.Printf("failed to upload first part: %v", err)
+		return
+	}
+
+	uploadStatus, err := client.GetUploadStatus(ctx, &proto.GetUploadStatusRequest{
+		UploadId: session.GetUploadId(),
+	})
+	if err != nil {
+		log.Printf("failed to get upload status: %v", err)
+		return
+	}
+	fmt.Printf("Upload %s has %d of %d bytes\n",
+		uploadStatus.GetUploadId(), uploadStatus.GetBytesReceived(), uploadStatus.GetDeclaredSize())
+
+	offset := uploadStatus.GetBytesReceived()
+	if err := uploadChunk(client, session.GetUploadId(), offset, fileContent[offset:]); err != nil {
+		log.Printf("failed to upload second part: %v", err)
+		return
+	}
+
+	response, err := client.CompleteUpload(ctx, &proto.CompleteUploadRequest{
+		UploadId: session.GetUploadId(),
+	})
+	if err != nil {
+		log.Printf("failed to complete upload: %v", err)
+		return
+	}
+
+	fmt.Printf("Resumable upload successful: ID=%s, Filename=%s, Size=%d\n",
+		response.GetId(), response.GetFilename(), response.GetSize())
+}
+
+func uploadChunk(client proto.FileServiceClient, uploadID string, offset uint64, data []byte) error {
+	stream, err := client.UploadChunk(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&proto.UploadChunkRequest{
+		Data: &proto.UploadChunkRequest_Header{
+			Header: &proto.UploadChunkHeader{
+				UploadId: uploadID,
+				Offset:   offset,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&proto.UploadChunkRequest{
+		Data: &proto.UploadChunkRequest_ChunkData{
+			ChunkData: data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = stream.CloseAndRecv()
+
+	return err
+}
+
+// bearerToken sends a token in the authorization metadata of every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity allows tokens over plaintext connections too,
+// for servers without TLS.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/grpc-file-storage-go/internal/config"
+	"github.com/grpc-file-storage-go/internal/encryption"
+	"github.com/grpc-file-storage-go/internal/repository"
+	"github.com/grpc-file-storage-go/internal/usecase"
+	"github.com/grpc-file-storage-go/pkg/database"
+)
+
+// rotate-keys re-wraps all data keys under the active master key. Run it
+// after adding a new master key and making it active; once it finishes,
+// the old master key can be removed from the configuration.
+func main() {
+	cfg := config.LoadConfig()
+
+	keyring, err := encryption.LoadKeyring(
+		cfg.EncryptionMasterKeys,
+		cfg.EncryptionMasterKeyFile,
+		cfg.EncryptionActiveKeyID,
+	)
+	if err != nil {
+		log.Fatalf("failed to load encryption keys: %v", err)
+	}
+	if keyring == nil {
+		log.Fatalf("no master keys configured")
+	}
+
+	db, err := database.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	rotation := usecase.NewKeyRotationUseCase(repository.NewPostgresDataKeyRepository(db), keyring)
+
+	rotated, err := rotation.RotateKeys(context.Background())
+	if err != nil {
+		log.Fatalf("key rotation stopped after %d keys: %v", rotated, err)
+	}
+
+	log.Printf("rotated %d data keys to master key %s", rotated, keyring.ActiveKeyID())
+}
@@ -1,22 +1,33 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net"
+	"path/filepath"
 
 	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/auth"
 	"github.com/grpc-file-storage-go/internal/config"
+	"github.com/grpc-file-storage-go/internal/encryption"
 	handlergrpc "github.com/grpc-file-storage-go/internal/handler/grpc"
 	"github.com/grpc-file-storage-go/internal/repository"
+	"github.com/grpc-file-storage-go/internal/storage"
+	"github.com/grpc-file-storage-go/internal/tlsconfig"
 	"github.com/grpc-file-storage-go/internal/usecase"
 	"github.com/grpc-file-storage-go/pkg/database"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
 	cfg := config.LoadConfig()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	db, err := database.NewDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
@@ -31,9 +42,62 @@ func main() {
 
 	fileRepo := repository.NewPostgresFileRepository(db)
 
-	fileUseCase := usecase.NewFileUseCase(fileRepo, cfg.StoragePath)
+	blobStore, err := newBlobStore(ctx, cfg)
+	if err != nil {
+		log.Fatalf("failed to create blob store: %v", err)
+	}
+
+	compression := usecase.CompressionPolicy{
+		Algorithm:    cfg.Compression,
+		ContentTypes: cfg.CompressionContentTypes,
+		MinSize:      cfg.CompressionMinSize,
+	}
+	if err := compression.Validate(); err != nil {
+		log.Fatalf("invalid compression config: %v", err)
+	}
+
+	keyring, err := encryption.LoadKeyring(
+		cfg.EncryptionMasterKeys,
+		cfg.EncryptionMasterKeyFile,
+		cfg.EncryptionActiveKeyID,
+	)
+	if err != nil {
+		log.Fatalf("failed to load encryption keys: %v", err)
+	}
+
+	fileUseCase := usecase.NewFileUseCase(fileRepo, blobStore, usecase.FileUseCaseOptions{
+		ContentTypes: usecase.ContentTypePolicy{
+			Allowed: cfg.AllowedContentTypes,
+			Denied:  cfg.DeniedContentTypes,
+		},
+		Compression:      compression,
+		ContentAddressed: cfg.ContentAddressedStorage,
+		StagingDir:       filepath.Join(cfg.StoragePath, ".staging"),
+		Keyring:          keyring,
+		KeepVersions:     int(cfg.KeepVersions),
+		TrashRetention:   cfg.TrashRetention,
+		Admins:           cfg.AuthAdmins,
+	})
 
-	fileHandler := handlergrpc.NewFileHandler(fileUseCase)
+	uploadSessionRepo := repository.NewPostgresUploadSessionRepository(db)
+
+	uploadSessionUseCase := usecase.NewUploadSessionUseCase(
+		uploadSessionRepo,
+		fileUseCase,
+		cfg.StoragePath,
+		cfg.UploadSessionTTL,
+	)
+
+	janitor := usecase.NewJanitor(
+		cfg.JanitorInterval,
+		usecase.CleanupTask{Name: "abandoned upload sessions", Run: uploadSessionUseCase.ExpireSessions},
+		usecase.CleanupTask{Name: "interrupted deletes", Run: fileUseCase.PurgePendingDeletes},
+		usecase.CleanupTask{Name: "unreferenced blobs", Run: fileUseCase.PurgeUnreferencedBlobs},
+		usecase.CleanupTask{Name: "expired trash", Run: fileUseCase.PurgeTrash},
+	)
+	go janitor.Run(ctx)
+
+	fileHandler := handlergrpc.NewFileHandler(fileUseCase, uploadSessionUseCase, cfg.MaxUploadSize)
 
 	limiter := handlergrpc.NewConcurrencyLimiter(
 		cfg.UploadLimit,
@@ -41,10 +105,32 @@ func main() {
 		cfg.ListLimit,
 	)
 
-	server := grpc.NewServer(
-		grpc.UnaryInterceptor(limiter.UnaryInterceptor()),
-		grpc.StreamInterceptor(limiter.StreamInterceptor()),
-	)
+	authenticator, err := newAuthenticator(cfg)
+	if err != nil {
+		log.Fatalf("failed to load auth config: %v", err)
+	}
+
+	serverOptions, err := newTransportOptions(cfg)
+	if err != nil {
+		log.Fatalf("failed to load TLS config: %v", err)
+	}
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{limiter.UnaryInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{limiter.StreamInterceptor()}
+	if authenticator != nil || cfg.TLSClientCAFile != "" {
+		// Callers are authenticated first, so rejected ones never take a
+		// concurrency slot.
+		authInterceptor := handlergrpc.NewAuthInterceptor(authenticator)
+		unaryInterceptors = append([]grpc.UnaryServerInterceptor{authInterceptor.UnaryInterceptor()}, unaryInterceptors...)
+		streamInterceptors = append([]grpc.StreamServerInterceptor{authInterceptor.StreamInterceptor()}, streamInterceptors...)
+	} else {
+		log.Printf("authentication is disabled; set AUTH_JWT_SECRET, AUTH_API_KEY_FILE or TLS_CLIENT_CA_FILE to enable it")
+	}
+
+	server := grpc.NewServer(append(serverOptions,
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)...)
 
 	proto.RegisterFileServiceServer(server, fileHandler)
 
@@ -58,3 +144,58 @@ func main() {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
+
+func newBlobStore(ctx context.Context, cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.StorageBackend {
+	case "local":
+		return storage.NewLocalBlobStore(cfg.StoragePath), nil
+	case "s3":
+		return storage.NewS3BlobStore(ctx, storage.S3Options{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UseSSL:          cfg.S3.UseSSL,
+			CreateBucket:    cfg.S3.CreateBucket,
+			PartSize:        cfg.S3.PartSize,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+func newTransportOptions(cfg *config.Config) ([]grpc.ServerOption, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		if cfg.TLSClientCAFile != "" {
+			return nil, fmt.Errorf("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		log.Printf("TLS is disabled; set TLS_CERT_FILE and TLS_KEY_FILE to enable it")
+
+		return nil, nil
+	}
+
+	tlsConfig, err := tlsconfig.NewServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
+func newAuthenticator(cfg *config.Config) (auth.Authenticator, error) {
+	authConfig := auth.Config{
+		JWTSecret:   []byte(cfg.AuthJWTSecret),
+		JWTIssuer:   cfg.AuthJWTIssuer,
+		JWTAudience: cfg.AuthJWTAudience,
+	}
+	if cfg.AuthAPIKeyFile != "" {
+		apiKeys, err := auth.LoadAPIKeys(cfg.AuthAPIKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		authConfig.APIKeys = apiKeys
+	}
+
+	return auth.NewAuthenticator(authConfig)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired,
+// wrongly signed or unknown.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Authenticator resolves bearer tokens to principals.
+type Authenticator interface {
+	Authenticate(token string) (*Principal, error)
+}
+
+// Config configures the accepted bearer tokens: JWTs signed with
+// JWTSecret, and static API keys by principal id.
+type Config struct {
+	JWTSecret   []byte
+	JWTIssuer   string
+	JWTAudience string
+	APIKeys     map[string]string
+}
+
+type authenticator struct {
+	jwt *jwtVerifier
+	// apiKeys maps the SHA-256 of each key to its principal id. Hashes
+	// have a fixed length, so comparing them takes the same time for any
+	// token.
+	apiKeys map[[sha256.Size]byte]string
+}
+
+// NewAuthenticator creates an authenticator for cfg. It returns nil if no
+// JWT secret or API key is configured, which disables authentication.
+func NewAuthenticator(cfg Config) (Authenticator, error) {
+	if len(cfg.JWTSecret) == 0 && len(cfg.APIKeys) == 0 {
+		return nil, nil
+	}
+
+	a := &authenticator{
+		apiKeys: make(map[[sha256.Size]byte]string, len(cfg.APIKeys)),
+	}
+	if len(cfg.JWTSecret) > 0 {
+		a.jwt = newJWTVerifier(cfg.JWTSecret, cfg.JWTIssuer, cfg.JWTAudience)
+	}
+	for id, key := range cfg.APIKeys {
+		if id == "" || key == "" {
+			return nil, fmt.Errorf("api key entry must be principal:key")
+		}
+		hash := sha256.Sum256([]byte(key))
+		if _, ok := a.apiKeys[hash]; ok {
+			return nil, fmt.Errorf("api key of %s is used more than once", id)
+		}
+		a.apiKeys[hash] = id
+	}
+
+	return a, nil
+}
+
+func (a *authenticator) Authenticate(token string) (*Principal, error) {
+	hash := sha256.Sum256([]byte(token))
+	for keyHash, id := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], keyHash[:]) == 1 {
+			return &Principal{ID: id}, nil
+		}
+	}
+
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		return a.jwt.verify(token)
+	}
+
+	return nil, ErrInvalidToken
+}
+
+// LoadAPIKeys reads a file with one "principal:key" entry per line. Blank
+// lines and lines starting with # are skipped.
+func LoadAPIKeys(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	keys := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || key == "" {
+			return nil, fmt.Errorf("%s:%d: api key entry must be principal:key", path, line)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate api key for %s", path, line, id)
+		}
+		keys[id] = key
+	}
+
+	return keys, scanner.Err()
+}
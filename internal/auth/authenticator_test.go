@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthenticator_DisabledWithoutCredentials(t *testing.T) {
+	authenticator, err := NewAuthenticator(Config{})
+
+	require.NoError(t, err)
+	assert.Nil(t, authenticator)
+}
+
+func TestAuthenticator_APIKeysAndJWTs(t *testing.T) {
+	authenticator, err := NewAuthenticator(Config{
+		JWTSecret: testSecret,
+		APIKeys:   map[string]string{"ci-bot": "s3cr3t-key"},
+	})
+	require.NoError(t, err)
+
+	principal, err := authenticator.Authenticate("s3cr3t-key")
+	require.NoError(t, err)
+	assert.Equal(t, "ci-bot", principal.ID)
+
+	token := signHS256(t, testSecret, map[string]any{"alg": "HS256"}, map[string]any{
+		"sub": "alice",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	principal, err = authenticator.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.ID)
+
+	_, err = authenticator.Authenticate("unknown-key")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys")
+	content := "# CI\nci-bot:key:with:colons\n\nbackup:other-key\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	keys, err := LoadAPIKeys(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ci-bot": "key:with:colons", "backup": "other-key"}, keys)
+}
+
+func TestLoadAPIKeys_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys")
+	require.NoError(t, os.WriteFile(path, []byte("no-separator\n"), 0600))
+
+	_, err := LoadAPIKeys(path)
+
+	assert.ErrorContains(t, err, ":1:")
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+)
+
+// ErrNoCommonName is returned for client certificates whose subject has no
+// common name to identify the client by.
+var ErrNoCommonName = errors.New("client certificate subject has no common name")
+
+// PrincipalFromCertificate identifies a client by the subject of its
+// verified certificate: the common name is its id and the organizational
+// units are its groups.
+func PrincipalFromCertificate(cert *x509.Certificate) (*Principal, error) {
+	if cert.Subject.CommonName == "" {
+		return nil, ErrNoCommonName
+	}
+
+	return &Principal{
+		ID:     cert.Subject.CommonName,
+		Groups: cert.Subject.OrganizationalUnit,
+	}, nil
+}
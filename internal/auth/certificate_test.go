@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PrincipalFromCertificate(t *testing.T) {
+	principal, err := PrincipalFromCertificate(&x509.Certificate{
+		Subject: pkix.Name{CommonName: "alice", OrganizationalUnit: []string{"finance", "engineering"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{ID: "alice", Groups: []string{"finance", "engineering"}}, principal)
+}
+
+func Test_PrincipalFromCertificate_NoCommonName(t *testing.T) {
+	_, err := PrincipalFromCertificate(&x509.Certificate{
+		Subject: pkix.Name{Organization: []string{"Example"}},
+	})
+
+	assert.ErrorIs(t, err, ErrNoCommonName)
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func signHS256(t *testing.T, secret []byte, header, claims map[string]any) string {
+	t.Helper()
+
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestVerifier(now time.Time) *jwtVerifier {
+	v := newJWTVerifier(testSecret, "https://issuer.example", "file-storage")
+	v.now = func() time.Time { return now }
+
+	return v
+}
+
+func TestJWTVerifier_Valid(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token := signHS256(t, testSecret, map[string]any{"alg": "HS256", "typ": "JWT"}, map[string]any{
+		"sub":    "alice",
+		"iss":    "https://issuer.example",
+		"aud":    []string{"other", "file-storage"},
+		"exp":    now.Add(time.Hour).Unix(),
+		"groups": []string{"team-alpha"},
+	})
+
+	principal, err := newTestVerifier(now).verify(token)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.ID)
+	assert.Equal(t, []string{"team-alpha"}, principal.Groups)
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	valid := map[string]any{
+		"sub": "alice",
+		"iss": "https://issuer.example",
+		"aud": "file-storage",
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	with := func(key string, value any) map[string]any {
+		claims := make(map[string]any, len(valid))
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	hs256 := map[string]any{"alg": "HS256"}
+
+	tests := map[string]string{
+		"wrong secret":    signHS256(t, []byte("other"), hs256, valid),
+		"alg none":        signHS256(t, testSecret, map[string]any{"alg": "none"}, valid),
+		"expired":         signHS256(t, testSecret, hs256, with("exp", now.Add(-time.Hour).Unix())),
+		"no expiry":       signHS256(t, testSecret, hs256, with("exp", nil)),
+		"not yet valid":   signHS256(t, testSecret, hs256, with("nbf", now.Add(time.Hour).Unix())),
+		"no subject":      signHS256(t, testSecret, hs256, with("sub", nil)),
+		"wrong issuer":    signHS256(t, testSecret, hs256, with("iss", "https://evil.example")),
+		"wrong audience":  signHS256(t, testSecret, hs256, with("aud", "other")),
+		"malformed":       "not.a.jwt",
+		"missing segment": "abc.def",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTestVerifier(now).verify(token)
+
+			assert.True(t, errors.Is(err, ErrInvalidToken), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "context"
+
+// Principal is an authenticated caller. ID identifies it across
+// authentication methods, and Groups are the groups it belongs to.
+type Principal struct {
+	ID     string
+	Groups []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of the caller, if it was
+// authenticated.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +15,72 @@ type Config struct {
 	UploadLimit    int64
 	DownloadLimit  int64
 	ListLimit      int64
+	// MaxUploadSize is the largest upload accepted, in bytes. Zero accepts
+	// uploads of any size.
+	MaxUploadSize int64
+
+	AllowedContentTypes []string
+	DeniedContentTypes  []string
+
+	UploadSessionTTL time.Duration
+	JanitorInterval  time.Duration
+
+	// StorageBackend is either "local" or "s3".
+	StorageBackend string
+	S3             S3Config
+	// ContentAddressedStorage deduplicates identical uploads.
+	ContentAddressedStorage bool
+
+	// Compression is "none", "gzip" or "zstd". It applies to uploads whose
+	// content type matches CompressionContentTypes, or to all uploads if the
+	// list is empty, unless they declare less than CompressionMinSize bytes.
+	Compression             string
+	CompressionContentTypes []string
+	CompressionMinSize      int64
+
+	// Master keys are "id:base64key" entries, from the list and from the
+	// key file, one per line. Content is encrypted only if keys are set.
+	EncryptionMasterKeys    []string
+	EncryptionMasterKeyFile string
+	EncryptionActiveKeyID   string
+
+	// KeepVersions is how many versions of each filename are kept unless
+	// set per filename. Zero keeps all of them.
+	KeepVersions int64
+	// TrashRetention is how long deleted files can be restored. Zero
+	// disables the trash.
+	TrashRetention time.Duration
+
+	// Callers must present a JWT signed with AuthJWTSecret or an API key
+	// from AuthAPIKeyFile, with one "principal:key" entry per line.
+	// Authentication is disabled if neither is set.
+	AuthJWTSecret   string
+	AuthJWTIssuer   string
+	AuthJWTAudience string
+	AuthAPIKeyFile  string
+	// AuthAdmins are the principals that can read and set the quota of
+	// everyone. Nobody can while authentication is disabled.
+	AuthAdmins []string
+
+	// The server speaks TLS if TLSCertFile and TLSKeyFile are set. With
+	// TLSClientCAFile it also requires client certificates signed by one of
+	// its CAs, and callers without a bearer token are identified by their
+	// certificate's subject. The files are reloaded when they change.
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+}
+
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	CreateBucket    bool
+	// PartSize is the size of each part of a multipart upload.
+	PartSize int64
 }
 
 type DatabaseConfig struct {
@@ -48,6 +116,47 @@ func LoadConfig() *Config {
 		UploadLimit:    getEnvInt64("UPLOAD_LIMIT", 10),
 		DownloadLimit:  getEnvInt64("DOWNLOAD_LIMIT", 10),
 		ListLimit:      getEnvInt64("LIST_LIMIT", 100),
+		MaxUploadSize:  getEnvInt64("MAX_UPLOAD_SIZE", 0),
+
+		AllowedContentTypes: getEnvList("ALLOWED_CONTENT_TYPES"),
+		DeniedContentTypes:  getEnvList("DENIED_CONTENT_TYPES"),
+
+		UploadSessionTTL: getEnvDuration("UPLOAD_SESSION_TTL", 24*time.Hour),
+		JanitorInterval:  getEnvDuration("JANITOR_INTERVAL", 10*time.Minute),
+
+		StorageBackend: getEnv("STORAGE_BACKEND", "local"),
+		S3: S3Config{
+			Endpoint:        getEnv("S3_ENDPOINT", "localhost:9000"),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			Bucket:          getEnv("S3_BUCKET", "files"),
+			AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			UseSSL:          getEnvBool("S3_USE_SSL", false),
+			CreateBucket:    getEnvBool("S3_CREATE_BUCKET", false),
+			PartSize:        getEnvInt64("S3_PART_SIZE", 16<<20),
+		},
+		ContentAddressedStorage: getEnvBool("CONTENT_ADDRESSED_STORAGE", false),
+
+		Compression:             getEnv("COMPRESSION", "none"),
+		CompressionContentTypes: getEnvList("COMPRESSION_CONTENT_TYPES"),
+		CompressionMinSize:      getEnvInt64("COMPRESSION_MIN_SIZE", 1024),
+
+		EncryptionMasterKeys:    getEnvList("ENCRYPTION_MASTER_KEYS"),
+		EncryptionMasterKeyFile: getEnv("ENCRYPTION_MASTER_KEY_FILE", ""),
+		EncryptionActiveKeyID:   getEnv("ENCRYPTION_ACTIVE_KEY_ID", ""),
+
+		KeepVersions:   getEnvInt64("KEEP_VERSIONS", 0),
+		TrashRetention: getEnvDuration("TRASH_RETENTION", 7*24*time.Hour),
+
+		AuthJWTSecret:   getEnv("AUTH_JWT_SECRET", ""),
+		AuthJWTIssuer:   getEnv("AUTH_JWT_ISSUER", ""),
+		AuthJWTAudience: getEnv("AUTH_JWT_AUDIENCE", ""),
+		AuthAPIKeyFile:  getEnv("AUTH_API_KEY_FILE", ""),
+		AuthAdmins:      getEnvList("AUTH_ADMINS"),
+
+		TLSCertFile:     getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:      getEnv("TLS_KEY_FILE", ""),
+		TLSClientCAFile: getEnv("TLS_CLIENT_CA_FILE", ""),
 	}
 }
 
@@ -76,3 +185,24 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+
+	return defaultValue
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
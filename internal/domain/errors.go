@@ -0,0 +1,19 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrUploadSessionBusy = errors.New("upload session is being written by another stream")
+	ErrInvalidOffset     = errors.New("offset is beyond the bytes received so far")
+	ErrUploadIncomplete  = errors.New("upload has not received all declared bytes")
+	ErrUploadTooLarge    = errors.New("upload exceeds its declared size")
+	ErrChecksumMismatch  = errors.New("checksum mismatch")
+	ErrInvalidRange      = errors.New("requested range is outside the file")
+	ErrContentTypeDenied = errors.New("content type is not allowed")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrInvalidPath       = errors.New("invalid path")
+	ErrPermissionDenied  = errors.New("permission denied")
+	ErrQuotaExceeded     = errors.New("quota exceeded")
+	ErrUnauthenticated   = errors.New("unauthenticated")
+)
@@ -2,16 +2,285 @@ package domain
 
 import "time"
 
+// File is a stored file. Filename is the name the client uploaded it with
+// and need not be unique; files of one owner sharing it are versions of one
+// another, numbered by Version. StorageName is a unique internal name and Path is
+// the key of the content in the blob store. BlobSHA256 is set when the
+// content is a deduplicated blob shared with other files. Size is the size
+// of the content as uploaded and StoredSize its size after Compression.
+// Encrypted content has a data key, wrapped with the master key KeyID.
+// Tags and Description are set by the client and searched along with the
+// filename; Labels are client-defined key/value pairs. OwnerID is the
+// principal that uploaded the file, empty if authentication was disabled;
+// files without an owner form one more namespace of filenames.
+// DeletedAt is set while the file is in the trash.
 type File struct {
-	ID        string    `json:"id"`
-	Filename  string    `json:"filename"`
-	Size      int64     `json:"size"`
-	Path      string    `json:"path"`
+	ID             string            `json:"id"`
+	Filename       string            `json:"filename"`
+	Version        int               `json:"version"`
+	StorageName    string            `json:"-"`
+	Size           int64             `json:"size"`
+	StoredSize     int64             `json:"stored_size"`
+	Compression    string            `json:"compression"`
+	ContentType    string            `json:"content_type"`
+	Tags           []string          `json:"tags,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	OwnerID        string            `json:"owner_id,omitempty"`
+	Path           string            `json:"path"`
+	ChecksumSHA256 string            `json:"checksum_sha256"`
+	ChecksumCRC32C uint32            `json:"checksum_crc32c"`
+	BlobSHA256     string            `json:"-"`
+	KeyID          string            `json:"-"`
+	WrappedKey     []byte            `json:"-"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	DeletedAt      *time.Time        `json:"deleted_at,omitempty"`
+}
+
+// Permission is a level of access to a file. Each level includes the ones
+// before it.
+type Permission string
+
+const (
+	PermissionNone  Permission = ""
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+	// PermissionOwner is held by the owner alone and allows sharing.
+	PermissionOwner Permission = "owner"
+)
+
+// Includes reports whether p allows everything other allows.
+func (p Permission) Includes(other Permission) bool {
+	return permissionLevels[p] >= permissionLevels[other]
+}
+
+var permissionLevels = map[Permission]int{
+	PermissionNone:  0,
+	PermissionRead:  1,
+	PermissionWrite: 2,
+	PermissionOwner: 3,
+}
+
+// Accessor is a caller whose access to files is checked: it can access
+// the files it owns and those shared with it or one of its groups.
+type Accessor struct {
+	ID     string
+	Groups []string
+}
+
+// GranteeType is the kind of principal a file is shared with.
+type GranteeType string
+
+const (
+	GranteeUser  GranteeType = "user"
+	GranteeGroup GranteeType = "group"
+)
+
+type Grantee struct {
+	Type GranteeType `json:"type"`
+	ID   string      `json:"id"`
+}
+
+// Grant shares all versions of a file, identified by its owner and
+// filename, with a user or a group.
+type Grant struct {
+	OwnerID    string     `json:"owner_id"`
+	Filename   string     `json:"filename"`
+	Grantee    Grantee    `json:"grantee"`
+	Permission Permission `json:"permission"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Quota limits what an owner can store. A limit of zero is unlimited.
+type Quota struct {
+	MaxBytes int64 `json:"max_bytes"`
+	MaxFiles int64 `json:"max_files"`
+}
+
+// Usage is what an owner stores: the size and number of all versions of
+// its files, including those in the trash, and the quota on them.
+type Usage struct {
+	OwnerID string `json:"owner_id"`
+	Bytes   int64  `json:"bytes"`
+	Files   int64  `json:"files"`
+	Quota
+}
+
+// Allows reports whether storing bytes more in files more files stays
+// within the quota.
+func (u *Usage) Allows(bytes, files int64) bool {
+	return (u.MaxBytes == 0 || u.Bytes+bytes <= u.MaxBytes) &&
+		(u.MaxFiles == 0 || u.Files+files <= u.MaxFiles)
+}
+
+// Folder is a folder files are organized in. Filenames are paths whose
+// last segment is the name of the file and whose other segments are its
+// folders, such as "projects/alpha/q3.pdf".
+type Folder struct {
+	Path string `json:"path"`
+	// OwnerID is the principal that created the folder, or "" if it was
+	// created while authentication was disabled.
+	OwnerID   string    `json:"owner_id"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// FolderListing is a page of the direct children of a folder: its
+// subfolders by path, then the latest version of its files by filename.
+type FolderListing struct {
+	Folders []Folder `json:"folders"`
+	Files   []File   `json:"files"`
+	// Next is where the following page starts, or nil on the last page.
+	Next *FolderCursor `json:"-"`
+}
+
+// FolderCursor is the position of a subfolder or file in a folder listing.
+// Files are also identified by FileID, since files of different owners can
+// share a path.
+type FolderCursor struct {
+	Path   string
+	FileID string
+	IsFile bool
+}
+
+// Compression algorithms content can be stored with.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
 type FileList struct {
 	Files []File `json:"files"`
 	Total int    `json:"total"`
+	// Next is where the following page starts, or nil on the last page.
+	Next *FileCursor `json:"-"`
+}
+
+// ListOptions selects a page of files in the order given by SortBy, which
+// defaults to the creation time, descending unless Ascending is set. Pages
+// are numbered, or start after the file at After, which stays stable while
+// files are uploaded. SkipTotal leaves FileList.Total unset to save
+// counting the files.
+type ListOptions struct {
+	Page      int
+	PageSize  int
+	SortBy    SortField
+	Ascending bool
+	After     *FileCursor
+	SkipTotal bool
+}
+
+// SortField is a file attribute listings can be sorted by.
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByFilename  SortField = "filename"
+	SortBySize      SortField = "size"
+)
+
+// FileCursor is the position of a file in a listing. It holds the file's
+// value for every sort field, so it is valid for any order.
+type FileCursor struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Filename  string
+	Size      int64
+	ID        string
+}
+
+// CursorOf returns the position of file in a listing.
+func CursorOf(file *File) *FileCursor {
+	return &FileCursor{
+		CreatedAt: file.CreatedAt,
+		UpdatedAt: file.UpdatedAt,
+		Filename:  file.Filename,
+		Size:      file.Size,
+		ID:        file.ID,
+	}
+}
+
+// FileFilter restricts a listing. Zero fields match everything. Name
+// filters are matched literally, NameContains case-insensitively.
+// ContentType is a media type, or a type such as "image/*". Files match
+// Labels if they have all of them. Size bounds are inclusive; time ranges
+// include their start and exclude their end. VisibleTo restricts the
+// listing to files the accessor can read.
+type FileFilter struct {
+	NamePrefix    string
+	NameContains  string
+	ContentType   string
+	Labels        map[string]string
+	MinSize       *int64
+	MaxSize       *int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	VisibleTo     *Accessor
+}
+
+// MetadataUpdate changes the client-defined metadata of a file. Labels in
+// SetLabels are added or replaced before RemoveLabels are removed. Tags
+// and Description are replaced if not nil.
+type MetadataUpdate struct {
+	SetLabels    map[string]string
+	RemoveLabels []string
+	Tags         []string
+	Description  *string
+}
+
+// SearchResult is a file matching a search. The highlights are the
+// filename and an excerpt of the description with matches wrapped in
+// <mark> and </mark>; they are not HTML-escaped.
+type SearchResult struct {
+	File                 File    `json:"file"`
+	Rank                 float64 `json:"rank"`
+	FilenameHighlight    string  `json:"filename_highlight"`
+	DescriptionHighlight string  `json:"description_highlight,omitempty"`
+}
+
+// ByteRange selects part of a file's content. A negative Length means
+// everything from Offset to the end of the file.
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// FileUpload holds what the client tells us about a file before sending
+// its content. Expected checksums are verified once all bytes arrived, and
+// an empty ContentType is detected from the first bytes.
+type FileUpload struct {
+	Filename       string            `json:"filename"`
+	ContentType    string            `json:"content_type,omitempty"`
+	Tags           []string          `json:"tags,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	DeclaredSize   *int64            `json:"declared_size,omitempty"`
+	ExpectedSHA256 string            `json:"expected_sha256,omitempty"`
+	ExpectedCRC32C *uint32           `json:"expected_crc32c,omitempty"`
+}
+
+// UploadSession tracks a resumable upload whose data is staged on disk
+// until the client completes it. Only OwnerID, the principal that
+// initiated it, can continue it.
+type UploadSession struct {
+	ID string `json:"id"`
+	FileUpload
+	OwnerID       string    `json:"owner_id,omitempty"`
+	BytesReceived int64     `json:"bytes_received"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// WrappedDataKey is a data key stored for either a single file or a
+// deduplicated blob, identified by FileID or BlobSHA256.
+type WrappedDataKey struct {
+	FileID     string
+	BlobSHA256 string
+	KeyID      string
+	WrappedKey []byte
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPathLength is the longest path, in characters, a file or folder can
+// have. It matches the size of the filename column.
+const MaxPathLength = 255
+
+// CleanPath normalizes a slash-separated path by dropping leading, trailing
+// and repeated slashes. Paths are never resolved, so "." and ".." are not
+// allowed as segments, and paths longer than MaxPathLength are rejected. The
+// root folder is "".
+func CleanPath(p string) (string, error) {
+	segments := make([]string, 0, strings.Count(p, "/")+1)
+	for _, segment := range strings.Split(p, "/") {
+		switch segment {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("%w: %q", ErrInvalidPath, p)
+		}
+		segments = append(segments, segment)
+	}
+
+	cleaned := strings.Join(segments, "/")
+	if utf8.RuneCountInString(cleaned) > MaxPathLength {
+		return "", fmt.Errorf("%w: longer than %d characters", ErrInvalidPath, MaxPathLength)
+	}
+
+	return cleaned, nil
+}
+
+// ParentPath returns the folder a clean path is in.
+func ParentPath(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+
+	return p[:i]
+}
+
+// BaseName returns the last segment of a clean path.
+func BaseName(p string) string {
+	return p[strings.LastIndex(p, "/")+1:]
+}
+
+// JoinPath returns the path of name in folder.
+func JoinPath(folder, name string) string {
+	if folder == "" {
+		return name
+	}
+
+	return folder + "/" + name
+}
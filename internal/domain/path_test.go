@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"/":                       "",
+		"report.pdf":              "report.pdf",
+		"/projects//alpha/q3.pdf": "projects/alpha/q3.pdf",
+		"projects/alpha/":         "projects/alpha",
+		"a.b/..c":                 "a.b/..c",
+	}
+	for path, want := range tests {
+		got, err := CleanPath(path)
+
+		assert.NoError(t, err, path)
+		assert.Equal(t, want, got, path)
+	}
+}
+
+func TestCleanPath_RejectsDotSegments(t *testing.T) {
+	for _, path := range []string{".", "..", "a/./b", "a/../b", "../a"} {
+		_, err := CleanPath(path)
+
+		assert.True(t, errors.Is(err, ErrInvalidPath), path)
+	}
+}
+
+func TestCleanPath_RejectsLongPaths(t *testing.T) {
+	path := strings.Repeat("a", MaxPathLength)
+	got, err := CleanPath("/" + path + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, path, got)
+
+	_, err = CleanPath(path + "a")
+	assert.True(t, errors.Is(err, ErrInvalidPath))
+}
+
+func TestParentPathAndBaseName(t *testing.T) {
+	assert.Equal(t, "projects/alpha", ParentPath("projects/alpha/q3.pdf"))
+	assert.Equal(t, "", ParentPath("q3.pdf"))
+	assert.Equal(t, "q3.pdf", BaseName("projects/alpha/q3.pdf"))
+	assert.Equal(t, "q3.pdf", BaseName("q3.pdf"))
+	assert.Equal(t, "projects/q3.pdf", JoinPath("projects", "q3.pdf"))
+	assert.Equal(t, "q3.pdf", JoinPath("", "q3.pdf"))
+}
@@ -0,0 +1,181 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Keyring holds the master keys that wrap per-file data keys. New data
+// keys are wrapped with the active key; the others are only kept to unwrap
+// keys of files that have not been rotated yet.
+type Keyring interface {
+	ActiveKeyID() string
+	// NewDataKey returns a random data key and the key wrapped with the
+	// active master key.
+	NewDataKey() (keyID string, dataKey, wrappedKey []byte, err error)
+	UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error)
+	// RewrapKey wraps a data key under the active master key.
+	RewrapKey(keyID string, wrappedKey []byte) (newKeyID string, newWrappedKey []byte, err error)
+}
+
+type keyring struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+}
+
+// NewKeyring creates a keyring from 32-byte master keys by id. activeID may
+// be empty if there is exactly one key.
+func NewKeyring(masterKeys map[string][]byte, activeID string) (Keyring, error) {
+	if len(masterKeys) == 0 {
+		return nil, fmt.Errorf("no master keys")
+	}
+	if activeID == "" {
+		if len(masterKeys) > 1 {
+			return nil, fmt.Errorf("active master key id is required with %d keys", len(masterKeys))
+		}
+		for id := range masterKeys {
+			activeID = id
+		}
+	}
+
+	keys := make(map[string]cipher.AEAD, len(masterKeys))
+	for id, key := range masterKeys {
+		if len(key) != DataKeySize {
+			return nil, fmt.Errorf("master key %s must be %d bytes, got %d", id, DataKeySize, len(key))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		keys[id] = aead
+	}
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("active master key %s is not configured", activeID)
+	}
+
+	return &keyring{
+		keys:     keys,
+		activeID: activeID,
+	}, nil
+}
+
+// LoadKeyring builds a keyring from "id:base64key" entries and an optional
+// file with one such entry per line. It returns nil if no keys are
+// configured, which disables encryption.
+func LoadKeyring(entries []string, keyFile, activeID string) (Keyring, error) {
+	if keyFile != "" {
+		fileEntries, err := readKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	masterKeys := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("master key entry must be id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %s: %w", id, err)
+		}
+		if _, ok := masterKeys[id]; ok {
+			return nil, fmt.Errorf("duplicate master key %s", id)
+		}
+		masterKeys[id] = key
+	}
+
+	return NewKeyring(masterKeys, activeID)
+}
+
+func readKeyFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	return entries, scanner.Err()
+}
+
+func (k *keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+func (k *keyring) NewDataKey() (string, []byte, []byte, error) {
+	dataKey := make([]byte, DataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", nil, nil, err
+	}
+
+	wrappedKey, err := k.wrap(k.activeID, dataKey)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return k.activeID, dataKey, wrappedKey, nil
+}
+
+func (k *keyring) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("master key %s is not configured", keyID)
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, ErrAuthentication
+	}
+
+	nonce, sealed := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+
+	return dataKey, nil
+}
+
+func (k *keyring) RewrapKey(keyID string, wrappedKey []byte) (string, []byte, error) {
+	dataKey, err := k.UnwrapKey(keyID, wrappedKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rewrapped, err := k.wrap(k.activeID, dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return k.activeID, rewrapped, nil
+}
+
+// wrap seals dataKey with a random nonce, which is prepended. The key id is
+// authenticated so a wrapped key cannot be passed off as another key's.
+func (k *keyring) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	aead := k.keys[keyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
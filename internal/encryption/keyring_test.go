@@ -0,0 +1,36 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Keyring_RewrapKey(t *testing.T) {
+	oldKey := make([]byte, DataKeySize)
+	newKey := make([]byte, DataKeySize)
+	rand.Read(oldKey)
+	rand.Read(newKey)
+
+	old, err := NewKeyring(map[string][]byte{"k1": oldKey}, "")
+	require.NoError(t, err)
+	keyID, dataKey, wrapped, err := old.NewDataKey()
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+
+	rotated, err := NewKeyring(map[string][]byte{"k1": oldKey, "k2": newKey}, "k2")
+	require.NoError(t, err)
+	newKeyID, rewrapped, err := rotated.RewrapKey(keyID, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", newKeyID)
+
+	unwrapped, err := rotated.UnwrapKey(newKeyID, rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	_, err = rotated.UnwrapKey("k1", rewrapped)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+}
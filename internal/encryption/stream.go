@@ -0,0 +1,184 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Content is encrypted with AES-256-GCM in segments of SegmentSize
+// plaintext bytes, each followed by its authentication tag. The nonce of a
+// segment is its index plus a flag marking the last segment, so segments
+// cannot be reordered, and truncation is detected because the last segment
+// read is not flagged as such. Nonces never repeat because every file has
+// its own data key.
+const (
+	SegmentSize = 64 * 1024
+	Overhead    = 16
+
+	DataKeySize = 32
+)
+
+var ErrAuthentication = errors.New("encrypted content failed authentication")
+
+// SegmentStart returns the segment holding the plaintext byte at offset and
+// the offset of that segment in the encrypted content.
+func SegmentStart(offset int64) (segment, encryptedOffset int64) {
+	segment = offset / SegmentSize
+
+	return segment, segment * (SegmentSize + Overhead)
+}
+
+type writer struct {
+	aead    cipher.AEAD
+	w       io.Writer
+	buf     []byte
+	segment int64
+	closed  bool
+}
+
+// NewWriter encrypts everything written to it into w. Close must be called
+// to write the last segment; it does not close w.
+func NewWriter(w io.Writer, dataKey []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		aead: aead,
+		w:    w,
+		buf:  make([]byte, 0, SegmentSize+Overhead),
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full segment is only sealed once more data arrives, because
+		// until then it might be the last one.
+		if len(w.buf) == SegmentSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):SegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	return w.seal(true)
+}
+
+func (w *writer) seal(last bool) error {
+	sealed := w.aead.Seal(w.buf[:0], segmentNonce(w.segment, last), w.buf, nil)
+	if _, err := w.w.Write(sealed); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	w.segment++
+
+	return nil
+}
+
+type reader struct {
+	aead    cipher.AEAD
+	r       *bufio.Reader
+	buf     []byte
+	plain   []byte
+	segment int64
+	done    bool
+}
+
+// NewReader decrypts content read from r, which must start at the
+// beginning of segment firstSegment.
+func NewReader(r io.Reader, dataKey []byte, firstSegment int64) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{
+		aead:    aead,
+		r:       bufio.NewReaderSize(r, SegmentSize+Overhead),
+		buf:     make([]byte, SegmentSize+Overhead),
+		segment: firstSegment,
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+func (r *reader) open() error {
+	n, err := io.ReadFull(r.r, r.buf)
+	if err == io.EOF {
+		return ErrAuthentication
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	last := err == io.ErrUnexpectedEOF
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.aead.Open(r.buf[:0], segmentNonce(r.segment, last), r.buf[:n], nil)
+	if err != nil {
+		return ErrAuthentication
+	}
+	r.plain = plain
+	r.segment++
+	r.done = last
+
+	return nil
+}
+
+func segmentNonce(segment int64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], uint64(segment))
+	if last {
+		nonce[11] = 1
+	}
+
+	return nonce
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
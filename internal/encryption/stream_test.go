@@ -0,0 +1,84 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+
+	var encrypted bytes.Buffer
+	w, err := NewWriter(&encrypted, key)
+	require.NoError(t, err)
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return encrypted.Bytes()
+}
+
+func Test_Stream_RoundTrip(t *testing.T) {
+	key := make([]byte, DataKeySize)
+	rand.Read(key)
+
+	for _, size := range []int{0, 1, SegmentSize, SegmentSize + 1, 3*SegmentSize - 7} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+
+		encrypted := encrypt(t, key, plain)
+		segments := size/SegmentSize + 1
+		if size > 0 && size%SegmentSize == 0 {
+			segments--
+		}
+		assert.Equal(t, size+segments*Overhead, len(encrypted))
+
+		r, err := NewReader(bytes.NewReader(encrypted), key, 0)
+		require.NoError(t, err)
+		decrypted, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, plain, decrypted)
+	}
+}
+
+func Test_Stream_ReadFromSegment(t *testing.T) {
+	key := make([]byte, DataKeySize)
+	rand.Read(key)
+	plain := make([]byte, 3*SegmentSize+100)
+	rand.Read(plain)
+	encrypted := encrypt(t, key, plain)
+
+	offset := int64(2*SegmentSize + 50)
+	segment, encryptedOffset := SegmentStart(offset)
+	r, err := NewReader(bytes.NewReader(encrypted[encryptedOffset:]), key, segment)
+	require.NoError(t, err)
+	decrypted, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, plain[segment*SegmentSize:], decrypted)
+}
+
+func Test_Stream_DetectsTamperingAndTruncation(t *testing.T) {
+	key := make([]byte, DataKeySize)
+	rand.Read(key)
+	plain := make([]byte, 2*SegmentSize+10)
+	encrypted := encrypt(t, key, plain)
+
+	tampered := bytes.Clone(encrypted)
+	tampered[10] ^= 1
+	r, err := NewReader(bytes.NewReader(tampered), key, 0)
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	truncated := encrypted[:2*(SegmentSize+Overhead)]
+	r, err = NewReader(bytes.NewReader(truncated), key, 0)
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"github.com/grpc-file-storage-go/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptor rejects calls without a valid bearer token in their
+// "authorization" metadata or a verified client certificate, and attaches
+// the caller's principal to the context of the others. A token takes
+// precedence over the certificate.
+type AuthInterceptor struct {
+	authenticator auth.Authenticator
+}
+
+// NewAuthInterceptor accepts tokens resolved by authenticator. If it is nil,
+// only client certificates are accepted.
+func NewAuthInterceptor(authenticator auth.Authenticator) *AuthInterceptor {
+	return &AuthInterceptor{
+		authenticator: authenticator,
+	}
+}
+
+func (a *AuthInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (a *AuthInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := a.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func (a *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		if cert := clientCertificate(ctx); cert != nil {
+			principal, err := auth.PrincipalFromCertificate(cert)
+			if err != nil {
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+
+			return auth.WithPrincipal(ctx, principal), nil
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata or a client certificate is required")
+	}
+	if a.authenticator == nil {
+		return nil, status.Error(codes.Unauthenticated, "bearer tokens are not accepted")
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+
+	principal, err := a.authenticator.Authenticate(strings.TrimSpace(token))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// clientCertificate returns the certificate the caller presented over TLS,
+// if it was verified.
+func clientCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// authenticatedStream is a server stream whose context carries the
+// caller's principal.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
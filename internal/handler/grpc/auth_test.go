@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/grpc-file-storage-go/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAuthInterceptor(t *testing.T) *AuthInterceptor {
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		APIKeys: map[string]string{"ci-bot": "s3cr3t-key"},
+	})
+	require.NoError(t, err)
+
+	return NewAuthInterceptor(authenticator)
+}
+
+func Test_AuthInterceptor_AttachesPrincipal(t *testing.T) {
+	interceptor := newTestAuthInterceptor(t).UnaryInterceptor()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer s3cr3t-key"))
+
+	var principal *auth.Principal
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.PrincipalFromContext(ctx)
+		return nil, nil
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, principal)
+	assert.Equal(t, "ci-bot", principal.ID)
+}
+
+func Test_AuthInterceptor_RejectsMissingOrInvalidTokens(t *testing.T) {
+	interceptor := newTestAuthInterceptor(t).UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler must not be called")
+		return nil, nil
+	}
+
+	tests := map[string]metadata.MD{
+		"no metadata":  nil,
+		"basic scheme": metadata.Pairs("authorization", "Basic s3cr3t-key"),
+		"unknown key":  metadata.Pairs("authorization", "Bearer wrong-key"),
+	}
+	for name, md := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func Test_AuthInterceptor_Stream(t *testing.T) {
+	interceptor := newTestAuthInterceptor(t).StreamInterceptor()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer s3cr3t-key"))
+
+	var principal *auth.Principal
+	err := interceptor(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		principal, _ = auth.PrincipalFromContext(stream.Context())
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, principal)
+	assert.Equal(t, "ci-bot", principal.ID)
+}
+
+// withClientCertificate returns ctx as if the caller had presented cert
+// over TLS and it was verified.
+func withClientCertificate(ctx context.Context, cert *x509.Certificate) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		},
+	})
+}
+
+func Test_AuthInterceptor_ClientCertificate(t *testing.T) {
+	interceptor := NewAuthInterceptor(nil).UnaryInterceptor()
+	ctx := withClientCertificate(context.Background(), &x509.Certificate{
+		Subject: pkix.Name{CommonName: "alice", OrganizationalUnit: []string{"finance"}},
+	})
+
+	var principal *auth.Principal
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.PrincipalFromContext(ctx)
+		return nil, nil
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, principal)
+	assert.Equal(t, "alice", principal.ID)
+	assert.Equal(t, []string{"finance"}, principal.Groups)
+}
+
+func Test_AuthInterceptor_TokenOverridesClientCertificate(t *testing.T) {
+	interceptor := newTestAuthInterceptor(t).UnaryInterceptor()
+	ctx := withClientCertificate(context.Background(), &x509.Certificate{Subject: pkix.Name{CommonName: "alice"}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer s3cr3t-key"))
+
+	var principal *auth.Principal
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.PrincipalFromContext(ctx)
+		return nil, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ci-bot", principal.ID)
+}
+
+func Test_AuthInterceptor_CertificatesOnly(t *testing.T) {
+	interceptor := NewAuthInterceptor(nil).UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler must not be called")
+		return nil, nil
+	}
+
+	tests := map[string]context.Context{
+		"bearer token": metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer s3cr3t-key")),
+		"no common name": withClientCertificate(context.Background(), &x509.Certificate{
+			Subject: pkix.Name{Organization: []string{"Example"}},
+		}),
+		"plaintext": context.Background(),
+	}
+	for name, ctx := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError maps usecase errors to gRPC status errors. Errors that
+// already carry a status are returned unchanged.
+func toStatusError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrUploadSessionBusy):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrContentTypeDenied), errors.Is(err, domain.ErrInvalidPath):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidOffset), errors.Is(err, domain.ErrInvalidRange),
+		errors.Is(err, domain.ErrUploadTooLarge):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, domain.ErrUploadIncomplete):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
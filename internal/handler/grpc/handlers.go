@@ -2,74 +2,227 @@ package grpc
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
 	"github.com/grpc-file-storage-go/internal/usecase"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Metadata keys sent with DownloadFile so clients can resume and verify
+// the content. fileSizeKey always carries the full size, not the range.
+// "content-type" itself is reserved by gRPC, hence the prefix.
+const (
+	fileSizeKey       = "x-file-size"
+	contentTypeKey    = "x-content-type"
+	checksumSHA256Key = "x-checksum-sha256"
+	checksumCRC32CKey = "x-checksum-crc32c"
+	// contentEncodingKey is set when the content is sent compressed, as
+	// stored, because the client accepts its encoding.
+	contentEncodingKey = "x-content-encoding"
+)
+
 type fileHandler struct {
 	proto.UnimplementedFileServiceServer
-	fileUseCase usecase.FileUseCase
+	fileUseCase          usecase.FileUseCase
+	uploadSessionUseCase usecase.UploadSessionUseCase
+	maxUploadSize        int64
 }
 
-func NewFileHandler(fileUseCase usecase.FileUseCase) proto.FileServiceServer {
+// NewFileHandler creates the gRPC file service. A maxUploadSize of zero or
+// less disables the upload size limit.
+func NewFileHandler(
+	fileUseCase usecase.FileUseCase,
+	uploadSessionUseCase usecase.UploadSessionUseCase,
+	maxUploadSize int64,
+) proto.FileServiceServer {
 	return &fileHandler{
-		fileUseCase: fileUseCase,
+		fileUseCase:          fileUseCase,
+		uploadSessionUseCase: uploadSessionUseCase,
+		maxUploadSize:        maxUploadSize,
 	}
 }
 
 func (h *fileHandler) UploadFile(stream proto.FileService_UploadFileServer) error {
-	var fileInfo *proto.FileInfo
-	var data []byte
+	req, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	fileInfo := req.GetInfo()
+	if fileInfo == nil {
+		return status.Error(codes.InvalidArgument, "file info is required")
+	}
+	upload, err := h.fileUploadFromInfo(fileInfo)
+	if err != nil {
+		return err
+	}
+
+	var file *domain.File
+	err = h.pipeUpload(
+		func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			chunk, ok := req.Data.(*proto.UploadFileRequest_ChunkData)
+			if !ok {
+				return nil, status.Error(codes.InvalidArgument, "file info must be sent only once, before any chunk")
+			}
+
+			return chunk.ChunkData, nil
+		},
+		0,
+		func(r io.Reader) error {
+			file, err = h.fileUseCase.UploadFile(stream.Context(), upload, r)
+			return err
+		},
+	)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	return stream.SendAndClose(toUploadFileResponse(file))
+}
+
+// fileUploadFromInfo validates the client's file info and converts it to
+// the usecase representation.
+func (h *fileHandler) fileUploadFromInfo(info *proto.FileInfo) (domain.FileUpload, error) {
+	upload := domain.FileUpload{
+		Filename:       info.Filename,
+		ContentType:    info.ContentType,
+		Tags:           info.Tags,
+		Description:    info.Description,
+		Labels:         info.Labels,
+		ExpectedSHA256: strings.ToLower(info.ExpectedSha256),
+		ExpectedCRC32C: info.ExpectedCrc32C,
+	}
+
+	if err := validateTags(upload.Tags); err != nil {
+		return upload, err
+	}
+	if err := validateDescription(upload.Description); err != nil {
+		return upload, err
+	}
+	if err := validateLabels(upload.Labels); err != nil {
+		return upload, err
+	}
 
+	if info.DeclaredSize != nil {
+		if h.exceedsUploadLimit(info.GetDeclaredSize()) {
+			return upload, h.uploadTooLargeError()
+		}
+		size := int64(info.GetDeclaredSize())
+		upload.DeclaredSize = &size
+	}
+
+	if upload.ExpectedSHA256 != "" {
+		if decoded, err := hex.DecodeString(upload.ExpectedSHA256); err != nil || len(decoded) != sha256.Size {
+			return upload, status.Error(codes.InvalidArgument, "expected sha256 must be 64 hex characters")
+		}
+	}
+
+	return upload, nil
+}
+
+func toUploadFileResponse(file *domain.File) *proto.UploadFileResponse {
+	return &proto.UploadFileResponse{
+		Id:             file.ID,
+		Filename:       file.Filename,
+		Size:           uint64(file.Size),
+		ChecksumSha256: file.ChecksumSHA256,
+		ChecksumCrc32C: file.ChecksumCRC32C,
+		ContentType:    file.ContentType,
+		Version:        uint32(file.Version),
+	}
+}
+
+// pipeUpload feeds chunks returned by next into consume through an io.Pipe,
+// so only one chunk is held in memory at a time. received is the number of
+// bytes already stored before this stream and counts towards the size limit.
+func (h *fileHandler) pipeUpload(next func() ([]byte, error), received uint64, consume func(io.Reader) error) error {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		err := consume(pr)
+		// Unblock the receive loop if the consumer stopped reading early.
+		pr.CloseWithError(io.ErrClosedPipe)
+		done <- err
+	}()
+
+	if err := h.receiveChunks(next, received, pw); err != nil {
+		pw.CloseWithError(err)
+		<-done
+
+		return err
+	}
+	pw.Close()
+
+	return <-done
+}
+
+// receiveChunks writes chunks into w until next reports io.EOF. It stops
+// without error when w is closed by the reader.
+func (h *fileHandler) receiveChunks(next func() ([]byte, error), received uint64, w io.Writer) error {
 	for {
-		req, err := stream.Recv()
+		chunk, err := next()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return status.Error(codes.Internal, err.Error())
+			return err
 		}
-		switch x := req.Data.(type) {
-		case *proto.UploadFileRequest_Info:
-			fileInfo = x.Info
-		case *proto.UploadFileRequest_ChunkData:
-			data = append(data, x.ChunkData...)
+
+		received += uint64(len(chunk))
+		if h.exceedsUploadLimit(received) {
+			return h.uploadTooLargeError()
 		}
-	}
 
-	if fileInfo == nil {
-		return status.Error(codes.InvalidArgument, "file info is required")
-	}
-	file, err := h.fileUseCase.UploadFile(
-		stream.Context(),
-		fileInfo.Filename,
-		&bytesReader{data: data},
-	)
-	if err != nil {
-		return status.Error(codes.Internal, err.Error())
+		if _, err := w.Write(chunk); err != nil {
+			return nil
+		}
 	}
+}
 
-	return stream.SendAndClose(&proto.UploadFileResponse{
-		Id:       file.ID,
-		Filename: file.Filename,
-		Size:     uint32(file.Size),
-	})
+func (h *fileHandler) exceedsUploadLimit(size uint64) bool {
+	return h.maxUploadSize > 0 && size > uint64(h.maxUploadSize)
+}
+
+func (h *fileHandler) uploadTooLargeError() error {
+	return status.Errorf(codes.ResourceExhausted,
+		"upload exceeds maximum size of %d bytes", h.maxUploadSize)
 }
 
 func (h *fileHandler) DownloadFile(req *proto.DownloadFileRequest, stream proto.FileService_DownloadFileServer) error {
-	_, reader, err := h.fileUseCase.DownLoadFile(stream.Context(), req.Filename)
+	if req.Offset > math.MaxInt64 || req.GetLength() > math.MaxInt64 {
+		return status.Error(codes.OutOfRange, domain.ErrInvalidRange.Error())
+	}
+	byteRange := domain.ByteRange{Offset: int64(req.Offset), Length: -1}
+	if req.Length != nil {
+		byteRange.Length = int64(req.GetLength())
+	}
+
+	fileID, err := h.resolveFileID(stream.Context(), req.FileId, req.Filename)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "does not exist") {
-			return status.Error(codes.NotFound, err.Error())
-		}
-		return status.Error(codes.Internal, err.Error())
+		return err
+	}
+
+	file, reader, encoding, err := h.openDownload(stream.Context(), fileID, byteRange, req.AcceptEncoding)
+	if err != nil {
+		return toStatusError(err)
 	}
 
 	if reader == nil {
@@ -80,69 +233,355 @@ func (h *fileHandler) DownloadFile(req *proto.DownloadFileRequest, stream proto.
 		defer closer.Close()
 	}
 
+	checksums := metadata.Pairs(
+		checksumSHA256Key, file.ChecksumSHA256,
+		checksumCRC32CKey, strconv.FormatUint(uint64(file.ChecksumCRC32C), 10),
+	)
+	header := metadata.Join(metadata.Pairs(
+		fileSizeKey, strconv.FormatInt(file.Size, 10),
+		contentTypeKey, file.ContentType,
+	), checksums)
+	if encoding != "" {
+		header.Set(contentEncodingKey, encoding)
+	}
+	if err := stream.SendHeader(header); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	stream.SetTrailer(checksums)
+
 	buffer := make([]byte, 64*1024)
 	for {
 		n, err := reader.Read(buffer)
+		if n > 0 {
+			if err := stream.Send(&proto.DownloadFileResponse{
+				ChunkData: buffer[:n],
+			}); err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return status.Error(codes.Internal, err.Error())
 		}
+	}
 
-		if err := stream.Send(&proto.DownloadFileResponse{
-			ChunkData: buffer[:n],
-		}); err != nil {
-			return status.Error(codes.Internal, err.Error())
+	return nil
+}
+
+// openDownload returns the content to send for a download. A whole file
+// stored compressed with an encoding the client accepts is sent as stored,
+// and its encoding is returned.
+func (h *fileHandler) openDownload(ctx context.Context, fileID string, byteRange domain.ByteRange, acceptEncoding []string) (*domain.File, io.Reader, string, error) {
+	if byteRange.Offset == 0 && byteRange.Length < 0 && len(acceptEncoding) > 0 {
+		file, err := h.fileUseCase.GetFileByID(ctx, fileID)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if file.Compression != domain.CompressionNone && slices.Contains(acceptEncoding, file.Compression) {
+			file, reader, err := h.fileUseCase.DownloadStored(ctx, fileID)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			return file, reader, file.Compression, nil
 		}
 	}
 
-	return nil
+	file, reader, err := h.fileUseCase.DownLoadFile(ctx, fileID, byteRange)
+
+	return file, reader, "", err
+}
+
+func (h *fileHandler) GetFileMetadata(ctx context.Context, req *proto.GetFileMetadataRequest) (*proto.FileMetadata, error) {
+	var file *domain.File
+	var err error
+
+	switch key := req.Key.(type) {
+	case *proto.GetFileMetadataRequest_FileId:
+		file, err = h.fileUseCase.GetFileByID(ctx, key.FileId)
+	case *proto.GetFileMetadataRequest_Filename:
+		file, err = h.fileUseCase.GetFileByName(ctx, key.Filename)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "file id or filename is required")
+	}
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileMetadata(file), nil
+}
+
+func (h *fileHandler) DeleteFile(ctx context.Context, req *proto.DeleteFileRequest) (*proto.DeleteFileResponse, error) {
+	fileID, err := h.resolveFileID(ctx, req.FileId, req.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.fileUseCase.DeleteFile(ctx, fileID); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.DeleteFileResponse{}, nil
 }
 
 func (h *fileHandler) ListFiles(ctx context.Context, req *proto.ListFilesRequest) (*proto.ListFilesResponse, error) {
-	page := int(req.Page)
+	sortBy, ok := sortFields[req.SortBy]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown sort field %v", req.SortBy)
+	}
+	page, pageSize := pageOrDefault(req.Page, req.PageSize)
+	opts := domain.ListOptions{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    sortBy,
+		Ascending: req.Ascending,
+		SkipTotal: req.SkipTotalCount,
+	}
+	if req.PageToken != "" {
+		cursor, err := decodePageToken(req.PageToken, sortBy, req.Ascending)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		opts.After = cursor
+	}
+
+	fileList, err := h.fileUseCase.ListFiles(ctx, toFileFilter(req.Filter), opts)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := toListFilesResponse(fileList, !req.SkipTotalCount)
+	if fileList.Next != nil {
+		resp.NextPageToken, err = encodePageToken(fileList.Next, sortBy, req.Ascending)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return resp, nil
+}
+
+var sortFields = map[proto.SortField]domain.SortField{
+	proto.SortField_SORT_FIELD_UNSPECIFIED: domain.SortByCreatedAt,
+	proto.SortField_SORT_FIELD_CREATED_AT:  domain.SortByCreatedAt,
+	proto.SortField_SORT_FIELD_UPDATED_AT:  domain.SortByUpdatedAt,
+	proto.SortField_SORT_FIELD_FILENAME:    domain.SortByFilename,
+	proto.SortField_SORT_FIELD_SIZE:        domain.SortBySize,
+}
+
+func toFileFilter(filter *proto.FileFilter) domain.FileFilter {
+	if filter == nil {
+		return domain.FileFilter{}
+	}
+
+	fileFilter := domain.FileFilter{
+		NamePrefix:   filter.FilenamePrefix,
+		NameContains: filter.FilenameContains,
+		ContentType:  filter.ContentType,
+		Labels:       filter.Labels,
+	}
+	if filter.MinSize != nil {
+		minSize := clampSize(*filter.MinSize)
+		fileFilter.MinSize = &minSize
+	}
+	if filter.MaxSize != nil {
+		maxSize := clampSize(*filter.MaxSize)
+		fileFilter.MaxSize = &maxSize
+	}
+	fileFilter.CreatedAfter = toTime(filter.CreatedAfter)
+	fileFilter.CreatedBefore = toTime(filter.CreatedBefore)
+	fileFilter.UpdatedAfter = toTime(filter.UpdatedAfter)
+	fileFilter.UpdatedBefore = toTime(filter.UpdatedBefore)
+
+	return fileFilter
+}
+
+func clampSize(size uint64) int64 {
+	if size > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(size)
+}
+
+func toTime(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	t := timestamp.AsTime()
+
+	return &t
+}
+
+func (h *fileHandler) SearchFiles(ctx context.Context, req *proto.SearchFilesRequest) (*proto.SearchFilesResponse, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	page, pageSize := pageOrDefault(req.Page, req.PageSize)
+
+	results, err := h.fileUseCase.SearchFiles(ctx, req.Query, page, pageSize)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &proto.SearchFilesResponse{Results: make([]*proto.SearchResult, len(results))}
+	for i := range results {
+		resp.Results[i] = &proto.SearchResult{
+			File:                 toFileMetadata(&results[i].File),
+			Rank:                 float32(results[i].Rank),
+			FilenameHighlight:    results[i].FilenameHighlight,
+			DescriptionHighlight: results[i].DescriptionHighlight,
+		}
+	}
+
+	return resp, nil
+}
+
+func (h *fileHandler) ListTrash(ctx context.Context, req *proto.ListTrashRequest) (*proto.ListFilesResponse, error) {
+	page, pageSize := pageOrDefault(req.Page, req.PageSize)
+
+	fileList, err := h.fileUseCase.ListTrash(ctx, page, pageSize)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toListFilesResponse(fileList, true), nil
+}
+
+func (h *fileHandler) RestoreFile(ctx context.Context, req *proto.RestoreFileRequest) (*proto.FileMetadata, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	file, err := h.fileUseCase.RestoreFile(ctx, req.FileId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileMetadata(file), nil
+}
+
+func (h *fileHandler) PurgeFile(ctx context.Context, req *proto.PurgeFileRequest) (*proto.PurgeFileResponse, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	if err := h.fileUseCase.PurgeFile(ctx, req.FileId); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.PurgeFileResponse{}, nil
+}
+
+func pageOrDefault(page, pageSize int32) (int, int) {
 	if page == 0 {
 		page = 1
 	}
-	pageSize := int(req.PageSize)
 	if pageSize == 0 {
 		pageSize = 20
 	}
 
-	fileList, err := h.fileUseCase.ListFiles(ctx, page, pageSize)
+	return int(page), int(pageSize)
+}
+
+func toListFilesResponse(fileList *domain.FileList, withTotal bool) *proto.ListFilesResponse {
+	files := make([]*proto.FileMetadata, len(fileList.Files))
+	for i := range fileList.Files {
+		files[i] = toFileMetadata(&fileList.Files[i])
+	}
+
+	resp := &proto.ListFilesResponse{Files: files}
+	if withTotal {
+		total := int32(fileList.Total)
+		resp.TotalCount = &total
+	}
+
+	return resp
+}
+
+func (h *fileHandler) ListVersions(ctx context.Context, req *proto.ListVersionsRequest) (*proto.ListVersionsResponse, error) {
+	if req.Filename == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
+	}
+
+	versions, err := h.fileUseCase.ListVersions(ctx, req.Filename)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, toStatusError(err)
 	}
 
-	files := make([]*proto.FileMetadata, len(fileList.Files))
-	for i, file := range fileList.Files {
-		files[i] = &proto.FileMetadata{
-			Filename:  file.Filename,
-			Size:      uint32(file.Size),
-			CreatedAt: timestamppb.New(file.CreatedAt),
-			UpdatedAt: timestamppb.New(file.UpdatedAt),
-		}
+	metadata := make([]*proto.FileMetadata, len(versions))
+	for i := range versions {
+		metadata[i] = toFileMetadata(&versions[i])
 	}
 
-	return &proto.ListFilesResponse{
-		Files:      files,
-		TotalCount: int32(fileList.Total),
-	}, nil
+	return &proto.ListVersionsResponse{Versions: metadata}, nil
 }
 
-type bytesReader struct {
-	data []byte
-	pos  int
+func (h *fileHandler) RestoreVersion(ctx context.Context, req *proto.RestoreVersionRequest) (*proto.UploadFileResponse, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	file, err := h.fileUseCase.RestoreVersion(ctx, req.FileId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toUploadFileResponse(file), nil
 }
 
-func (r *bytesReader) Read(p []byte) (n int, err error) {
-	if r.pos >= len(r.data) {
-		return 0, io.EOF
+func (h *fileHandler) SetVersionRetention(ctx context.Context, req *proto.SetVersionRetentionRequest) (*proto.SetVersionRetentionResponse, error) {
+	if req.Filename == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
 	}
 
-	n = copy(p, r.data[r.pos:])
-	r.pos += n
+	if err := h.fileUseCase.SetVersionRetention(ctx, req.Filename, int(req.KeepVersions)); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.SetVersionRetentionResponse{}, nil
+}
+
+// resolveFileID supports requests that still address files by the
+// deprecated filename field by looking up the newest file with that name.
+func (h *fileHandler) resolveFileID(ctx context.Context, fileID, filename string) (string, error) {
+	if fileID != "" {
+		return fileID, nil
+	}
+	if filename == "" {
+		return "", status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	file, err := h.fileUseCase.GetFileByName(ctx, filename)
+	if err != nil {
+		return "", toStatusError(err)
+	}
+
+	return file.ID, nil
+}
+
+func toFileMetadata(file *domain.File) *proto.FileMetadata {
+	metadata := &proto.FileMetadata{
+		Id:             file.ID,
+		Filename:       file.Filename,
+		Version:        uint32(file.Version),
+		Size:           uint64(file.Size),
+		StoredSize:     uint64(file.StoredSize),
+		Compression:    file.Compression,
+		ContentType:    file.ContentType,
+		ChecksumSha256: file.ChecksumSHA256,
+		ChecksumCrc32C: file.ChecksumCRC32C,
+		Tags:           file.Tags,
+		Description:    file.Description,
+		Labels:         file.Labels,
+		OwnerId:        file.OwnerID,
+		CreatedAt:      timestamppb.New(file.CreatedAt),
+		UpdatedAt:      timestamppb.New(file.UpdatedAt),
+	}
+	if file.DeletedAt != nil {
+		metadata.DeletedAt = timestamppb.New(*file.DeletedAt)
+	}
 
-	return n, nil
+	return metadata
 }
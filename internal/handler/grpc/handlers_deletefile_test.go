@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_DeleteFile_Success(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("DeleteFile", mock.Anything, "file-uuid").Return(nil)
+
+	resp, err := handler.DeleteFile(context.Background(), &proto.DeleteFileRequest{
+		FileId: "file-uuid",
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_DeleteFile_NotFound(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("DeleteFile", mock.Anything, "missing-uuid").Return(domain.ErrNotFound)
+
+	_, err := handler.DeleteFile(context.Background(), &proto.DeleteFileRequest{
+		FileId: "missing-uuid",
+	})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_DeleteFile_MissingFileID(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.DeleteFile(context.Background(), &proto.DeleteFileRequest{})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "DeleteFile")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -20,6 +21,8 @@ import (
 type MockDownloadFileStream struct {
 	mock.Mock
 	sentChunks []*proto.DownloadFileResponse
+	header     metadata.MD
+	trailer    metadata.MD
 }
 
 func (m *MockDownloadFileStream) Send(response *proto.DownloadFileResponse) error {
@@ -32,11 +35,13 @@ func (m *MockDownloadFileStream) SetHeader(metadata.MD) error {
 	return nil
 }
 
-func (m *MockDownloadFileStream) SendHeader(metadata.MD) error {
+func (m *MockDownloadFileStream) SendHeader(md metadata.MD) error {
+	m.header = metadata.Join(m.header, md)
 	return nil
 }
 
-func (m *MockDownloadFileStream) SetTrailer(metadata.MD) {
+func (m *MockDownloadFileStream) SetTrailer(md metadata.MD) {
+	m.trailer = metadata.Join(m.trailer, md)
 }
 
 func (m *MockDownloadFileStream) Context() context.Context {
@@ -53,14 +58,17 @@ func (m *MockDownloadFileStream) RecvMsg(interface{}) error {
 
 func Test_DownloadFile_Success(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	testFileContent := "Hello, this is test file content for download!"
 	testFile := &domain.File{
-		ID:       "download-uuid",
-		Filename: "test_download.txt",
-		Size:     int64(len(testFileContent)),
-		Path:     "/storage/test_download.txt",
+		ID:             "download-uuid",
+		Filename:       "test_download.txt",
+		Size:           int64(len(testFileContent)),
+		Path:           "/storage/test_download.txt",
+		ContentType:    "text/plain",
+		ChecksumSHA256: "d2a84f4b8b650937ec8f73cd8be2c74add5a911ba64df27458ed8229da804a26",
+		ChecksumCRC32C: 1234,
 	}
 
 	fileReader := io.NopCloser(strings.NewReader(testFileContent))
@@ -68,7 +76,8 @@ func Test_DownloadFile_Success(t *testing.T) {
 	mockUseCase.On(
 		"DownLoadFile",
 		mock.Anything,
-		"test_download.txt").
+		"download-uuid",
+		domain.ByteRange{Offset: 0, Length: -1}).
 		Return(testFile, fileReader, nil)
 
 	mockStream := new(MockDownloadFileStream)
@@ -79,7 +88,7 @@ func Test_DownloadFile_Success(t *testing.T) {
 		Maybe()
 
 	err := handler.DownloadFile(&proto.DownloadFileRequest{
-		Filename: "test_download.txt",
+		FileId: "download-uuid",
 	},
 		mockStream)
 
@@ -93,22 +102,28 @@ func Test_DownloadFile_Success(t *testing.T) {
 		allData = append(allData, chunk.ChunkData...)
 	}
 	assert.Equal(t, testFileContent, string(allData))
+	assert.Equal(t, []string{testFile.ChecksumSHA256}, mockStream.trailer.Get("x-checksum-sha256"))
+	assert.Equal(t, []string{"1234"}, mockStream.trailer.Get("x-checksum-crc32c"))
+	assert.Equal(t, []string{strconv.Itoa(len(testFileContent))}, mockStream.header.Get("x-file-size"))
+	assert.Equal(t, []string{testFile.ChecksumSHA256}, mockStream.header.Get("x-checksum-sha256"))
+	assert.Equal(t, []string{"text/plain"}, mockStream.header.Get("x-content-type"))
 }
 
 func Test_DownloadFile_FileNotFound(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	mockUseCase.On(
 		"DownLoadFile",
 		mock.Anything,
-		"nonexistent.txt").
+		"nonexistent-uuid",
+		domain.ByteRange{Offset: 0, Length: -1}).
 		Return(nil, nil, errors.New("file not found"))
 
 	mockStream := new(MockDownloadFileStream)
 
 	err := handler.DownloadFile(&proto.DownloadFileRequest{
-		Filename: "nonexistent.txt",
+		FileId: "nonexistent-uuid",
 	},
 		mockStream)
 
@@ -125,7 +140,7 @@ func Test_DownloadFile_FileNotFound(t *testing.T) {
 
 func Test_DownloadFile_EmptyFile(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	testFile := &domain.File{
 		ID:       "empty-uuid",
@@ -137,13 +152,13 @@ func Test_DownloadFile_EmptyFile(t *testing.T) {
 
 	mockUseCase.On(
 		"DownLoadFile",
-		mock.Anything, "empty.txt").
+		mock.Anything, "empty-uuid", domain.ByteRange{Offset: 0, Length: -1}).
 		Return(testFile, fileReader, nil)
 
 	mockStream := new(MockDownloadFileStream)
 
 	err := handler.DownloadFile(&proto.DownloadFileRequest{
-		Filename: "empty.txt",
+		FileId: "empty-uuid",
 	},
 		mockStream)
 
@@ -154,7 +169,7 @@ func Test_DownloadFile_EmptyFile(t *testing.T) {
 
 func Test_DownloadFile_SendError(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	testFileContent := "Test content that will fail to send"
 	testFile := &domain.File{
@@ -166,7 +181,7 @@ func Test_DownloadFile_SendError(t *testing.T) {
 
 	fileReader := io.NopCloser(strings.NewReader(testFileContent))
 
-	mockUseCase.On("DownLoadFile", mock.Anything, "error.txt").Return(testFile, fileReader, nil)
+	mockUseCase.On("DownLoadFile", mock.Anything, "error-uuid", domain.ByteRange{Offset: 0, Length: -1}).Return(testFile, fileReader, nil)
 
 	mockStream := new(MockDownloadFileStream)
 	mockStream.On(
@@ -175,7 +190,7 @@ func Test_DownloadFile_SendError(t *testing.T) {
 		Return(errors.New("network error"))
 
 	err := handler.DownloadFile(&proto.DownloadFileRequest{
-		Filename: "error.txt",
+		FileId: "error-uuid",
 	},
 		mockStream)
 
@@ -187,3 +202,154 @@ func Test_DownloadFile_SendError(t *testing.T) {
 	mockUseCase.AssertExpectations(t)
 	mockStream.AssertCalled(t, "Send", mock.AnythingOfType("*proto.DownloadFileResponse"))
 }
+
+func Test_DownloadFile_Range(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	testFile := &domain.File{
+		ID:       "range-uuid",
+		Filename: "range.txt",
+		Size:     100,
+	}
+	length := uint64(5)
+
+	mockUseCase.On(
+		"DownLoadFile",
+		mock.Anything,
+		"range-uuid",
+		domain.ByteRange{Offset: 90, Length: 5}).
+		Return(testFile, io.NopCloser(strings.NewReader("abcde")), nil)
+
+	mockStream := new(MockDownloadFileStream)
+	mockStream.On("Send", mock.AnythingOfType("*proto.DownloadFileResponse")).Return(nil)
+
+	err := handler.DownloadFile(&proto.DownloadFileRequest{
+		FileId: "range-uuid",
+		Offset: 90,
+		Length: &length,
+	},
+		mockStream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"100"}, mockStream.header.Get("x-file-size"))
+	assert.Equal(t, "abcde", string(mockStream.sentChunks[0].ChunkData))
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_DownloadFile_InvalidRange(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On(
+		"DownLoadFile",
+		mock.Anything,
+		"range-uuid",
+		domain.ByteRange{Offset: 200, Length: -1}).
+		Return(nil, domain.ErrInvalidRange)
+
+	mockStream := new(MockDownloadFileStream)
+
+	err := handler.DownloadFile(&proto.DownloadFileRequest{
+		FileId: "range-uuid",
+		Offset: 200,
+	},
+		mockStream)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.OutOfRange, grpcStatus.Code())
+	mockStream.AssertNotCalled(t, "Send", mock.AnythingOfType("*proto.DownloadFileResponse"))
+}
+
+func Test_DownloadFile_DeprecatedFilename(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	testFile := &domain.File{
+		ID:       "report-uuid",
+		Filename: "report.pdf",
+		Size:     6,
+	}
+
+	mockUseCase.On("GetFileByName", mock.Anything, "report.pdf").Return(testFile, nil)
+	mockUseCase.On(
+		"DownLoadFile",
+		mock.Anything,
+		"report-uuid",
+		domain.ByteRange{Offset: 0, Length: -1}).
+		Return(testFile, io.NopCloser(strings.NewReader("report")), nil)
+
+	mockStream := new(MockDownloadFileStream)
+	mockStream.On("Send", mock.AnythingOfType("*proto.DownloadFileResponse")).Return(nil)
+
+	err := handler.DownloadFile(&proto.DownloadFileRequest{
+		Filename: "report.pdf",
+	},
+		mockStream)
+
+	assert.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_DownloadFile_PassesThroughAcceptedEncoding(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	testFile := &domain.File{
+		ID:          "log-uuid",
+		Filename:    "app.log",
+		Size:        1000,
+		StoredSize:  4,
+		Compression: domain.CompressionZstd,
+	}
+
+	mockUseCase.On("GetFileByID", mock.Anything, "log-uuid").Return(testFile, nil)
+	mockUseCase.On("DownloadStored", mock.Anything, "log-uuid").
+		Return(testFile, io.NopCloser(strings.NewReader("zstd")), nil)
+
+	mockStream := new(MockDownloadFileStream)
+	mockStream.On("Send", mock.AnythingOfType("*proto.DownloadFileResponse")).Return(nil)
+
+	err := handler.DownloadFile(&proto.DownloadFileRequest{
+		FileId:         "log-uuid",
+		AcceptEncoding: []string{"gzip", "zstd"},
+	},
+		mockStream)
+
+	assert.NoError(t, err)
+	mockUseCase.AssertNotCalled(t, "DownLoadFile", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, "zstd", string(mockStream.sentChunks[0].ChunkData))
+	assert.Equal(t, []string{"zstd"}, mockStream.header.Get("x-content-encoding"))
+	assert.Equal(t, []string{"1000"}, mockStream.header.Get("x-file-size"))
+}
+
+func Test_DownloadFile_DecompressesUnacceptedEncoding(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	testFile := &domain.File{
+		ID:          "log-uuid",
+		Filename:    "app.log",
+		Size:        5,
+		Compression: domain.CompressionZstd,
+	}
+
+	mockUseCase.On("GetFileByID", mock.Anything, "log-uuid").Return(testFile, nil)
+	mockUseCase.On("DownLoadFile", mock.Anything, "log-uuid", domain.ByteRange{Offset: 0, Length: -1}).
+		Return(testFile, io.NopCloser(strings.NewReader("plain")), nil)
+
+	mockStream := new(MockDownloadFileStream)
+	mockStream.On("Send", mock.AnythingOfType("*proto.DownloadFileResponse")).Return(nil)
+
+	err := handler.DownloadFile(&proto.DownloadFileRequest{
+		FileId:         "log-uuid",
+		AcceptEncoding: []string{"gzip"},
+	},
+		mockStream)
+
+	assert.NoError(t, err)
+	mockUseCase.AssertNotCalled(t, "DownloadStored", mock.Anything, mock.Anything)
+	assert.Equal(t, "plain", string(mockStream.sentChunks[0].ChunkData))
+	assert.Empty(t, mockStream.header.Get("x-content-encoding"))
+}
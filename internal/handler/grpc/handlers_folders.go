@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *fileHandler) CreateFolder(ctx context.Context, req *proto.CreateFolderRequest) (*proto.Folder, error) {
+	if req.Path == "" {
+		return nil, status.Error(codes.InvalidArgument, "path is required")
+	}
+
+	folder, err := h.fileUseCase.CreateFolder(ctx, req.Path)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFolder(folder), nil
+}
+
+func (h *fileHandler) ListFolder(ctx context.Context, req *proto.ListFolderRequest) (*proto.ListFolderResponse, error) {
+	// Tokens are tied to the cleaned path, so "a/" continues a listing of
+	// "a".
+	folder, err := domain.CleanPath(req.Path)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	var after *domain.FolderCursor
+	if req.PageToken != "" {
+		after, err = decodeFolderPageToken(req.PageToken, folder)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	listing, err := h.fileUseCase.ListFolder(ctx, folder, after, int(req.PageSize))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &proto.ListFolderResponse{
+		Folders: make([]*proto.Folder, 0, len(listing.Folders)),
+		Files:   make([]*proto.FileMetadata, 0, len(listing.Files)),
+	}
+	for i := range listing.Folders {
+		resp.Folders = append(resp.Folders, toFolder(&listing.Folders[i]))
+	}
+	for i := range listing.Files {
+		resp.Files = append(resp.Files, toFileMetadata(&listing.Files[i]))
+	}
+	if listing.Next != nil {
+		resp.NextPageToken, err = encodeFolderPageToken(listing.Next, folder)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return resp, nil
+}
+
+func (h *fileHandler) MoveFile(ctx context.Context, req *proto.MoveFileRequest) (*proto.FileMetadata, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	file, err := h.fileUseCase.MoveFile(ctx, req.FileId, req.Folder)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileMetadata(file), nil
+}
+
+func (h *fileHandler) RenameFile(ctx context.Context, req *proto.RenameFileRequest) (*proto.FileMetadata, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+	if req.NewName == "" {
+		return nil, status.Error(codes.InvalidArgument, "new name is required")
+	}
+
+	file, err := h.fileUseCase.RenameFile(ctx, req.FileId, req.NewName)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileMetadata(file), nil
+}
+
+func toFolder(folder *domain.Folder) *proto.Folder {
+	return &proto.Folder{
+		Path:      folder.Path,
+		CreatedAt: timestamppb.New(folder.CreatedAt),
+		OwnerId:   folder.OwnerID,
+	}
+}
@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_CreateFolder_AlreadyExists(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("CreateFolder", mock.Anything, "projects/alpha").Return(nil, domain.ErrAlreadyExists)
+
+	_, err := handler.CreateFolder(context.Background(), &proto.CreateFolderRequest{Path: "projects/alpha"})
+
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ListFolder_PageTokenRoundTrip(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("ListFolder", mock.Anything, "projects", (*domain.FolderCursor)(nil), 2).Return(&domain.FolderListing{
+		Folders: []domain.Folder{{Path: "projects/alpha"}},
+		Files:   []domain.File{{ID: "file-uuid", Filename: "projects/readme.md"}},
+		Next:    &domain.FolderCursor{Path: "projects/readme.md", FileID: "file-uuid", IsFile: true},
+	}, nil).Once()
+
+	first, err := handler.ListFolder(context.Background(), &proto.ListFolderRequest{Path: "/projects/", PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "projects/alpha", first.Folders[0].Path)
+	assert.Equal(t, "projects/readme.md", first.Files[0].Filename)
+	require.NotEmpty(t, first.NextPageToken)
+
+	after := &domain.FolderCursor{Path: "projects/readme.md", FileID: "file-uuid", IsFile: true}
+	mockUseCase.On("ListFolder", mock.Anything, "projects", after, 2).Return(&domain.FolderListing{}, nil).Once()
+
+	second, err := handler.ListFolder(context.Background(), &proto.ListFolderRequest{
+		Path:      "projects",
+		PageSize:  2,
+		PageToken: first.NextPageToken,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, second.NextPageToken)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ListFolder_TokenFromOtherFolder(t *testing.T) {
+	handler := NewFileHandler(new(MockFileUseCase), nil, 0)
+
+	token, err := encodeFolderPageToken(&domain.FolderCursor{Path: "a/b"}, "a")
+	require.NoError(t, err)
+
+	_, err = handler.ListFolder(context.Background(), &proto.ListFolderRequest{Path: "c", PageToken: token})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_ListFolder_InvalidPath(t *testing.T) {
+	handler := NewFileHandler(new(MockFileUseCase), nil, 0)
+
+	_, err := handler.ListFolder(context.Background(), &proto.ListFolderRequest{Path: "a/../b"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_MoveFile(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("MoveFile", mock.Anything, "file-uuid", "archive").Return(&domain.File{
+		ID:       "file-uuid",
+		Filename: "archive/q3.pdf",
+	}, nil)
+
+	resp, err := handler.MoveFile(context.Background(), &proto.MoveFileRequest{FileId: "file-uuid", Folder: "archive"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "archive/q3.pdf", resp.Filename)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_MoveFile_FolderNotFound(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("MoveFile", mock.Anything, "file-uuid", "missing").Return(nil, domain.ErrNotFound)
+
+	_, err := handler.MoveFile(context.Background(), &proto.MoveFileRequest{FileId: "file-uuid", Folder: "missing"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func Test_RenameFile_MissingName(t *testing.T) {
+	handler := NewFileHandler(new(MockFileUseCase), nil, 0)
+
+	_, err := handler.RenameFile(context.Background(), &proto.RenameFileRequest{FileId: "file-uuid"})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_CreateFolder_ReturnsOwner(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("CreateFolder", mock.Anything, "projects/alpha").Return(&domain.Folder{Path: "projects/alpha", OwnerID: "alice"}, nil)
+
+	folder, err := handler.CreateFolder(context.Background(), &proto.CreateFolderRequest{Path: "projects/alpha"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", folder.OwnerId)
+}
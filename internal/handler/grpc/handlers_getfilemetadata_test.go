@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_GetFileMetadata_ByID(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockUseCase.On("GetFileByID", mock.Anything, "file-uuid").Return(&domain.File{
+		ID:             "file-uuid",
+		Filename:       "report.pdf",
+		Size:           2048,
+		ChecksumSHA256: "abc",
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}, nil)
+
+	resp, err := handler.GetFileMetadata(context.Background(), &proto.GetFileMetadataRequest{
+		Key: &proto.GetFileMetadataRequest_FileId{FileId: "file-uuid"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "file-uuid", resp.Id)
+	assert.Equal(t, "report.pdf", resp.Filename)
+	assert.Equal(t, uint64(2048), resp.Size)
+	assert.Equal(t, "abc", resp.ChecksumSha256)
+	assert.True(t, createdAt.Equal(resp.CreatedAt.AsTime()))
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_GetFileMetadata_ByFilenameNotFound(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("GetFileByName", mock.Anything, "missing.txt").Return(nil, domain.ErrNotFound)
+
+	_, err := handler.GetFileMetadata(context.Background(), &proto.GetFileMetadataRequest{
+		Key: &proto.GetFileMetadataRequest_Filename{Filename: "missing.txt"},
+	})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_GetFileMetadata_MissingKey(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.GetFileMetadata(context.Background(), &proto.GetFileMetadataRequest{})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+}
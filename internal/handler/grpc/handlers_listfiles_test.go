@@ -3,17 +3,21 @@ package grpc
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grpc-file-storage-go/api/proto"
 	"github.com/grpc-file-storage-go/internal/domain"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func Test_ListFiles(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	expectedFiles := &domain.FileList{
 		Files: []domain.File{
@@ -26,7 +30,11 @@ func Test_ListFiles(t *testing.T) {
 		Total: 1,
 	}
 
-	mockUseCase.On("ListFiles", mock.Anything, 1, 10).Return(expectedFiles, nil)
+	mockUseCase.On("ListFiles", mock.Anything, domain.FileFilter{}, domain.ListOptions{
+		Page:     1,
+		PageSize: 10,
+		SortBy:   domain.SortByCreatedAt,
+	}).Return(expectedFiles, nil)
 
 	resp, err := handler.ListFiles(context.Background(), &proto.ListFilesRequest{
 		Page:     1,
@@ -36,5 +44,121 @@ func Test_ListFiles(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(resp.Files))
 	assert.Equal(t, "file1.txt", resp.Files[0].Filename)
+	assert.Equal(t, int32(1), resp.GetTotalCount())
+	assert.Empty(t, resp.NextPageToken)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ListFiles_PageTokenRoundTrip(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	last := domain.File{ID: "2", Filename: "file2.txt", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 678901000, time.UTC)}
+	cursor := &domain.FileCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	mockUseCase.On("ListFiles", mock.Anything, domain.FileFilter{}, domain.ListOptions{
+		Page:      1,
+		PageSize:  2,
+		SortBy:    domain.SortByCreatedAt,
+		SkipTotal: true,
+	}).Return(&domain.FileList{
+		Files: []domain.File{{ID: "1", Filename: "file1.txt"}, last},
+		Next:  cursor,
+	}, nil)
+
+	resp, err := handler.ListFiles(context.Background(), &proto.ListFilesRequest{
+		PageSize:       2,
+		SkipTotalCount: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, resp.TotalCount)
+	assert.NotEmpty(t, resp.NextPageToken)
+
+	mockUseCase.On("ListFiles", mock.Anything, domain.FileFilter{}, mock.MatchedBy(func(opts domain.ListOptions) bool {
+		return opts.After != nil && opts.After.ID == "2" && opts.After.CreatedAt.Equal(last.CreatedAt)
+	})).Return(&domain.FileList{}, nil)
+
+	resp, err = handler.ListFiles(context.Background(), &proto.ListFilesRequest{
+		PageSize:  2,
+		PageToken: resp.NextPageToken,
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.NextPageToken)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ListFiles_InvalidPageToken(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.ListFiles(context.Background(), &proto.ListFilesRequest{PageToken: "not a token"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "ListFiles", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_ListFiles_UseCaseError(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("ListFiles", mock.Anything, mock.Anything, mock.Anything).Return((*domain.FileList)(nil), domain.ErrPermissionDenied)
+
+	_, err := handler.ListFiles(context.Background(), &proto.ListFilesRequest{})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func Test_ListFiles_FilterAndSort(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	minSize := int64(1024)
+	mockUseCase.On("ListFiles", mock.Anything, domain.FileFilter{
+		NamePrefix:   "reports/",
+		ContentType:  "application/*",
+		MinSize:      &minSize,
+		CreatedAfter: &createdAfter,
+	}, domain.ListOptions{
+		Page:      1,
+		PageSize:  20,
+		SortBy:    domain.SortBySize,
+		Ascending: true,
+	}).Return(&domain.FileList{}, nil)
+
+	minSizeRequested := uint64(1024)
+	_, err := handler.ListFiles(context.Background(), &proto.ListFilesRequest{
+		Filter: &proto.FileFilter{
+			FilenamePrefix: "reports/",
+			ContentType:    "application/*",
+			MinSize:        &minSizeRequested,
+			CreatedAfter:   timestamppb.New(createdAfter),
+		},
+		SortBy:    proto.SortField_SORT_FIELD_SIZE,
+		Ascending: true,
+	})
+
+	assert.NoError(t, err)
 	mockUseCase.AssertExpectations(t)
 }
+
+func Test_ListFiles_PageTokenFromOtherOrder(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	token, err := encodePageToken(&domain.FileCursor{ID: "1"}, domain.SortByFilename, true)
+	assert.NoError(t, err)
+
+	_, err = handler.ListFiles(context.Background(), &proto.ListFilesRequest{
+		PageToken: token,
+		SortBy:    proto.SortField_SORT_FIELD_SIZE,
+		Ascending: true,
+	})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+}
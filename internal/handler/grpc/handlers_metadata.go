@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits on the client-defined metadata of a file.
+const (
+	maxTags              = 50
+	maxTagLength         = 100
+	maxDescriptionLength = 4096
+	maxLabels            = 64
+	maxLabelKeyLength    = 63
+	maxLabelValueLength  = 256
+)
+
+func (h *fileHandler) UpdateFileMetadata(ctx context.Context, req *proto.UpdateFileMetadataRequest) (*proto.FileMetadata, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	update := domain.MetadataUpdate{
+		SetLabels:    req.SetLabels,
+		RemoveLabels: req.RemoveLabels,
+		Description:  req.Description,
+	}
+	if req.Tags != nil {
+		update.Tags = append([]string{}, req.Tags.Tags...)
+	}
+
+	if err := validateLabels(update.SetLabels); err != nil {
+		return nil, err
+	}
+	if err := validateTags(update.Tags); err != nil {
+		return nil, err
+	}
+	if update.Description != nil {
+		if err := validateDescription(*update.Description); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := h.fileUseCase.UpdateFileMetadata(ctx, req.FileId, update)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileMetadata(file), nil
+}
+
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return status.Errorf(codes.InvalidArgument, "at most %d tags are allowed", maxTags)
+	}
+	for _, tag := range tags {
+		if len(tag) > maxTagLength {
+			return status.Errorf(codes.InvalidArgument, "tags must be at most %d bytes", maxTagLength)
+		}
+	}
+
+	return nil
+}
+
+func validateDescription(description string) error {
+	if len(description) > maxDescriptionLength {
+		return status.Errorf(codes.InvalidArgument, "description must be at most %d bytes", maxDescriptionLength)
+	}
+
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return status.Errorf(codes.InvalidArgument, "at most %d labels are allowed", maxLabels)
+	}
+	for key, value := range labels {
+		if key == "" || len(key) > maxLabelKeyLength {
+			return status.Errorf(codes.InvalidArgument, "label keys must be 1 to %d bytes", maxLabelKeyLength)
+		}
+		if len(value) > maxLabelValueLength {
+			return status.Errorf(codes.InvalidArgument, "label %s must be at most %d bytes", key, maxLabelValueLength)
+		}
+	}
+
+	return nil
+}
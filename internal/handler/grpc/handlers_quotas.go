@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"math"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *fileHandler) GetUsage(ctx context.Context, req *proto.GetUsageRequest) (*proto.Usage, error) {
+	usage, err := h.fileUseCase.GetUsage(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toUsage(usage), nil
+}
+
+func (h *fileHandler) GetQuota(ctx context.Context, req *proto.GetQuotaRequest) (*proto.Usage, error) {
+	if req.OwnerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner id is required")
+	}
+
+	usage, err := h.fileUseCase.GetQuota(ctx, req.OwnerId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toUsage(usage), nil
+}
+
+func (h *fileHandler) SetQuota(ctx context.Context, req *proto.SetQuotaRequest) (*proto.Usage, error) {
+	if req.OwnerId == "" {
+		return nil, status.Error(codes.InvalidArgument, "owner id is required")
+	}
+	if req.MaxBytes > math.MaxInt64 || req.MaxFiles > math.MaxInt64 {
+		return nil, status.Error(codes.InvalidArgument, "quota limits must fit in a signed 64-bit integer")
+	}
+
+	usage, err := h.fileUseCase.SetQuota(ctx, req.OwnerId, domain.Quota{
+		MaxBytes: int64(req.MaxBytes),
+		MaxFiles: int64(req.MaxFiles),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toUsage(usage), nil
+}
+
+func toUsage(usage *domain.Usage) *proto.Usage {
+	return &proto.Usage{
+		OwnerId:  usage.OwnerID,
+		Bytes:    uint64(usage.Bytes),
+		Files:    uint64(usage.Files),
+		MaxBytes: uint64(usage.MaxBytes),
+		MaxFiles: uint64(usage.MaxFiles),
+	}
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_SetQuota(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	quota := domain.Quota{MaxBytes: 1 << 30, MaxFiles: 1000}
+	mockUseCase.On("SetQuota", mock.Anything, "team-a", quota).Return(&domain.Usage{
+		OwnerID: "team-a",
+		Bytes:   512,
+		Files:   3,
+		Quota:   quota,
+	}, nil)
+
+	usage, err := handler.SetQuota(context.Background(), &proto.SetQuotaRequest{
+		OwnerId:  "team-a",
+		MaxBytes: 1 << 30,
+		MaxFiles: 1000,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, &proto.Usage{OwnerId: "team-a", Bytes: 512, Files: 3, MaxBytes: 1 << 30, MaxFiles: 1000}, usage)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_SetQuota_InvalidArguments(t *testing.T) {
+	handler := NewFileHandler(new(MockFileUseCase), nil, 0)
+
+	_, err := handler.SetQuota(context.Background(), &proto.SetQuotaRequest{MaxBytes: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.SetQuota(context.Background(), &proto.SetQuotaRequest{OwnerId: "team-a", MaxBytes: math.MaxUint64})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_GetQuota_NotAdmin(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("GetQuota", mock.Anything, "team-a").Return(nil, domain.ErrPermissionDenied)
+
+	_, err := handler.GetQuota(context.Background(), &proto.GetQuotaRequest{OwnerId: "team-a"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_SearchFiles(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("SearchFiles", mock.Anything, "quarterly invoice", 1, 20).Return([]domain.SearchResult{{
+		File: domain.File{
+			ID:       "file-uuid",
+			Filename: "quarterly_invoice.pdf",
+			Tags:     []string{"finance"},
+		},
+		Rank:              0.8,
+		FilenameHighlight: "<mark>quarterly</mark>_<mark>invoice</mark>.pdf",
+	}}, nil)
+
+	resp, err := handler.SearchFiles(context.Background(), &proto.SearchFilesRequest{Query: "quarterly invoice"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 1)
+	assert.Equal(t, "file-uuid", resp.Results[0].File.Id)
+	assert.Equal(t, []string{"finance"}, resp.Results[0].File.Tags)
+	assert.Equal(t, float32(0.8), resp.Results[0].Rank)
+	assert.Equal(t, "<mark>quarterly</mark>_<mark>invoice</mark>.pdf", resp.Results[0].FilenameHighlight)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_SearchFiles_EmptyQuery(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.SearchFiles(context.Background(), &proto.SearchFilesRequest{Query: "  "})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "SearchFiles", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_SearchFiles_UseCaseError(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("SearchFiles", mock.Anything, "invoice", 1, 20).Return([]domain.SearchResult(nil), domain.ErrPermissionDenied)
+
+	_, err := handler.SearchFiles(context.Background(), &proto.SearchFilesRequest{Query: "invoice"})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var permissions = map[proto.Permission]domain.Permission{
+	proto.Permission_PERMISSION_READ:  domain.PermissionRead,
+	proto.Permission_PERMISSION_WRITE: domain.PermissionWrite,
+}
+
+func (h *fileHandler) ShareFile(ctx context.Context, req *proto.ShareFileRequest) (*proto.FileGrant, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+	grantee, err := toGrantee(req.Grantee)
+	if err != nil {
+		return nil, err
+	}
+	permission, ok := permissions[req.Permission]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "permission must be read or write, got %v", req.Permission)
+	}
+
+	grant, err := h.fileUseCase.ShareFile(ctx, req.FileId, grantee, permission)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toFileGrant(grant), nil
+}
+
+func (h *fileHandler) UnshareFile(ctx context.Context, req *proto.UnshareFileRequest) (*proto.UnshareFileResponse, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+	grantee, err := toGrantee(req.Grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.fileUseCase.UnshareFile(ctx, req.FileId, grantee); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.UnshareFileResponse{}, nil
+}
+
+func (h *fileHandler) ListFileGrants(ctx context.Context, req *proto.ListFileGrantsRequest) (*proto.ListFileGrantsResponse, error) {
+	if req.FileId == "" {
+		return nil, status.Error(codes.InvalidArgument, "file id is required")
+	}
+
+	grants, err := h.fileUseCase.ListFileGrants(ctx, req.FileId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &proto.ListFileGrantsResponse{
+		Grants: make([]*proto.FileGrant, 0, len(grants)),
+	}
+	for i := range grants {
+		resp.Grants = append(resp.Grants, toFileGrant(&grants[i]))
+	}
+
+	return resp, nil
+}
+
+func toGrantee(grantee *proto.Grantee) (domain.Grantee, error) {
+	switch {
+	case grantee.GetUserId() != "":
+		return domain.Grantee{Type: domain.GranteeUser, ID: grantee.GetUserId()}, nil
+	case grantee.GetGroup() != "":
+		return domain.Grantee{Type: domain.GranteeGroup, ID: grantee.GetGroup()}, nil
+	default:
+		return domain.Grantee{}, status.Error(codes.InvalidArgument, "grantee user id or group is required")
+	}
+}
+
+func toFileGrant(grant *domain.Grant) *proto.FileGrant {
+	fileGrant := &proto.FileGrant{
+		Grantee:   &proto.Grantee{},
+		CreatedAt: timestamppb.New(grant.CreatedAt),
+	}
+	switch grant.Grantee.Type {
+	case domain.GranteeUser:
+		fileGrant.Grantee.Kind = &proto.Grantee_UserId{UserId: grant.Grantee.ID}
+	case domain.GranteeGroup:
+		fileGrant.Grantee.Kind = &proto.Grantee_Group{Group: grant.Grantee.ID}
+	}
+	for protoPermission, permission := range permissions {
+		if permission == grant.Permission {
+			fileGrant.Permission = protoPermission
+		}
+	}
+
+	return fileGrant
+}
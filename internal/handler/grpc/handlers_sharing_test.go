@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ShareFile_Group(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	grantee := domain.Grantee{Type: domain.GranteeGroup, ID: "finance"}
+	mockUseCase.On("ShareFile", mock.Anything, "file-uuid", grantee, domain.PermissionWrite).Return(&domain.Grant{
+		Filename:   "reports/q3.pdf",
+		Grantee:    grantee,
+		Permission: domain.PermissionWrite,
+	}, nil)
+
+	grant, err := handler.ShareFile(context.Background(), &proto.ShareFileRequest{
+		FileId:     "file-uuid",
+		Grantee:    &proto.Grantee{Kind: &proto.Grantee_Group{Group: "finance"}},
+		Permission: proto.Permission_PERMISSION_WRITE,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "finance", grant.Grantee.GetGroup())
+	assert.Equal(t, proto.Permission_PERMISSION_WRITE, grant.Permission)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ShareFile_InvalidArguments(t *testing.T) {
+	handler := NewFileHandler(new(MockFileUseCase), nil, 0)
+
+	_, err := handler.ShareFile(context.Background(), &proto.ShareFileRequest{
+		FileId:     "file-uuid",
+		Permission: proto.Permission_PERMISSION_READ,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = handler.ShareFile(context.Background(), &proto.ShareFileRequest{
+		FileId:  "file-uuid",
+		Grantee: &proto.Grantee{Kind: &proto.Grantee_UserId{UserId: "bob"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_UnshareFile_NotOwner(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	grantee := domain.Grantee{Type: domain.GranteeUser, ID: "bob"}
+	mockUseCase.On("UnshareFile", mock.Anything, "file-uuid", grantee).Return(domain.ErrPermissionDenied)
+
+	_, err := handler.UnshareFile(context.Background(), &proto.UnshareFileRequest{
+		FileId:  "file-uuid",
+		Grantee: &proto.Grantee{Kind: &proto.Grantee_UserId{UserId: "bob"}},
+	})
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
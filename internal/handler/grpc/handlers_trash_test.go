@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ListTrash_ReportsDeletedAt(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockUseCase.On("ListTrash", mock.Anything, 1, 20).Return(&domain.FileList{
+		Files: []domain.File{{ID: "file-uuid", Filename: "report.pdf", DeletedAt: &deletedAt}},
+		Total: 1,
+	}, nil)
+
+	resp, err := handler.ListTrash(context.Background(), &proto.ListTrashRequest{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.GetTotalCount())
+	assert.Len(t, resp.Files, 1)
+	assert.True(t, deletedAt.Equal(resp.Files[0].DeletedAt.AsTime()))
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_RestoreFile(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("RestoreFile", mock.Anything, "file-uuid").Return(&domain.File{
+		ID:       "file-uuid",
+		Filename: "report.pdf",
+	}, nil)
+
+	resp, err := handler.RestoreFile(context.Background(), &proto.RestoreFileRequest{FileId: "file-uuid"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "file-uuid", resp.Id)
+	assert.Nil(t, resp.DeletedAt)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_PurgeFile_NotInTrash(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("PurgeFile", mock.Anything, "file-uuid").Return(domain.ErrNotFound)
+
+	_, err := handler.PurgeFile(context.Background(), &proto.PurgeFileRequest{FileId: "file-uuid"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_PurgeFile_MissingFileID(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.PurgeFile(context.Background(), &proto.PurgeFileRequest{})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+}
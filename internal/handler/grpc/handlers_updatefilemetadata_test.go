@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_UpdateFileMetadata(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	description := "Signed copy"
+	mockUseCase.On("UpdateFileMetadata", mock.Anything, "file-uuid", domain.MetadataUpdate{
+		SetLabels:    map[string]string{"retention": "legal"},
+		RemoveLabels: []string{"project"},
+		Tags:         []string{},
+		Description:  &description,
+	}).Return(&domain.File{
+		ID:          "file-uuid",
+		Description: description,
+		Labels:      map[string]string{"retention": "legal"},
+	}, nil)
+
+	resp, err := handler.UpdateFileMetadata(context.Background(), &proto.UpdateFileMetadataRequest{
+		FileId:       "file-uuid",
+		SetLabels:    map[string]string{"retention": "legal"},
+		RemoveLabels: []string{"project"},
+		Tags:         &proto.TagList{},
+		Description:  &description,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"retention": "legal"}, resp.Labels)
+	assert.Equal(t, description, resp.Description)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_UpdateFileMetadata_KeepsTagsUnlessSet(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("UpdateFileMetadata", mock.Anything, "file-uuid", mock.MatchedBy(func(update domain.MetadataUpdate) bool {
+		return update.Tags == nil && update.Description == nil
+	})).Return(&domain.File{ID: "file-uuid"}, nil)
+
+	_, err := handler.UpdateFileMetadata(context.Background(), &proto.UpdateFileMetadataRequest{
+		FileId:    "file-uuid",
+		SetLabels: map[string]string{"project": "alpha"},
+	})
+
+	assert.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_UpdateFileMetadata_InvalidLabel(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	_, err := handler.UpdateFileMetadata(context.Background(), &proto.UpdateFileMetadataRequest{
+		FileId:    "file-uuid",
+		SetLabels: map[string]string{"project": strings.Repeat("a", maxLabelValueLength+1)},
+	})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "UpdateFileMetadata", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_UpdateFileMetadata_NotFound(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("UpdateFileMetadata", mock.Anything, "missing", mock.Anything).Return(nil, domain.ErrNotFound)
+
+	_, err := handler.UpdateFileMetadata(context.Background(), &proto.UpdateFileMetadataRequest{FileId: "missing"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+}
@@ -2,7 +2,10 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/grpc-file-storage-go/api/proto"
@@ -19,8 +22,12 @@ type MockFileUseCase struct {
 	mock.Mock
 }
 
-func (m *MockFileUseCase) UploadFile(ctx context.Context, filename string, data io.Reader) (*domain.File, error) {
-	args := m.Called(ctx, filename, data)
+// UploadFile reads data to the end before recording the call, since the
+// handler is still writing it. Expectations match the content and the error
+// reading it ended with.
+func (m *MockFileUseCase) UploadFile(ctx context.Context, upload domain.FileUpload, data io.Reader) (*domain.File, error) {
+	content, err := io.ReadAll(data)
+	args := m.Called(ctx, upload, content, err)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -28,8 +35,8 @@ func (m *MockFileUseCase) UploadFile(ctx context.Context, filename string, data
 	return args.Get(0).(*domain.File), args.Error(1)
 }
 
-func (m *MockFileUseCase) DownLoadFile(ctx context.Context, filename string) (*domain.File, io.Reader, error) {
-	args := m.Called(ctx, filename)
+func (m *MockFileUseCase) DownLoadFile(ctx context.Context, filename string, byteRange domain.ByteRange) (*domain.File, io.Reader, error) {
+	args := m.Called(ctx, filename, byteRange)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(1)
 	}
@@ -37,16 +44,221 @@ func (m *MockFileUseCase) DownLoadFile(ctx context.Context, filename string) (*d
 	return args.Get(0).(*domain.File), args.Get(1).(io.Reader), args.Error(2)
 }
 
-func (m *MockFileUseCase) ListFiles(ctx context.Context, page, pageSize int) (*domain.FileList, error) {
+func (m *MockFileUseCase) DownloadStored(ctx context.Context, fileID string) (*domain.File, io.Reader, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+
+	return args.Get(0).(*domain.File), args.Get(1).(io.Reader), args.Error(2)
+}
+
+func (m *MockFileUseCase) ListFiles(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error) {
+	args := m.Called(ctx, filter, opts)
+
+	return args.Get(0).(*domain.FileList), args.Error(1)
+}
+
+func (m *MockFileUseCase) GetFileByID(ctx context.Context, id string) (*domain.File, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) GetFileByName(ctx context.Context, filename string) (*domain.File, error) {
+	args := m.Called(ctx, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) DeleteFile(ctx context.Context, filename string) error {
+	args := m.Called(ctx, filename)
+
+	return args.Error(0)
+}
+
+func (m *MockFileUseCase) PurgePendingDeletes(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFileUseCase) PurgeUnreferencedBlobs(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFileUseCase) UpdateFileMetadata(ctx context.Context, fileID string, update domain.MetadataUpdate) (*domain.File, error) {
+	args := m.Called(ctx, fileID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) SearchFiles(ctx context.Context, query string, page, pageSize int) ([]domain.SearchResult, error) {
+	args := m.Called(ctx, query, page, pageSize)
+
+	return args.Get(0).([]domain.SearchResult), args.Error(1)
+}
+
+func (m *MockFileUseCase) ListTrash(ctx context.Context, page, pageSize int) (*domain.FileList, error) {
 	args := m.Called(ctx, page, pageSize)
 
 	return args.Get(0).(*domain.FileList), args.Error(1)
 }
 
+func (m *MockFileUseCase) RestoreFile(ctx context.Context, fileID string) (*domain.File, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) PurgeFile(ctx context.Context, fileID string) error {
+	args := m.Called(ctx, fileID)
+
+	return args.Error(0)
+}
+
+func (m *MockFileUseCase) PurgeTrash(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFileUseCase) ListVersions(ctx context.Context, filename string) ([]domain.File, error) {
+	args := m.Called(ctx, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) RestoreVersion(ctx context.Context, fileID string) (*domain.File, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) SetVersionRetention(ctx context.Context, filename string, keepVersions int) error {
+	args := m.Called(ctx, filename, keepVersions)
+
+	return args.Error(0)
+}
+
+func (m *MockFileUseCase) CreateFolder(ctx context.Context, path string) (*domain.Folder, error) {
+	args := m.Called(ctx, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Folder), args.Error(1)
+}
+
+func (m *MockFileUseCase) ListFolder(ctx context.Context, path string, after *domain.FolderCursor, pageSize int) (*domain.FolderListing, error) {
+	args := m.Called(ctx, path, after, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.FolderListing), args.Error(1)
+}
+
+func (m *MockFileUseCase) MoveFile(ctx context.Context, fileID, folder string) (*domain.File, error) {
+	args := m.Called(ctx, fileID, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileUseCase) ShareFile(ctx context.Context, fileID string, grantee domain.Grantee, permission domain.Permission) (*domain.Grant, error) {
+	args := m.Called(ctx, fileID, grantee, permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Grant), args.Error(1)
+}
+
+func (m *MockFileUseCase) UnshareFile(ctx context.Context, fileID string, grantee domain.Grantee) error {
+	args := m.Called(ctx, fileID, grantee)
+
+	return args.Error(0)
+}
+
+func (m *MockFileUseCase) ListFileGrants(ctx context.Context, fileID string) ([]domain.Grant, error) {
+	args := m.Called(ctx, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Grant), args.Error(1)
+}
+
+func (m *MockFileUseCase) GetUsage(ctx context.Context) (*domain.Usage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Usage), args.Error(1)
+}
+
+func (m *MockFileUseCase) GetQuota(ctx context.Context, ownerID string) (*domain.Usage, error) {
+	args := m.Called(ctx, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Usage), args.Error(1)
+}
+
+func (m *MockFileUseCase) SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error) {
+	args := m.Called(ctx, ownerID, quota)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Usage), args.Error(1)
+}
+
+func (m *MockFileUseCase) CheckQuota(ctx context.Context, upload domain.FileUpload) error {
+	args := m.Called(ctx, upload)
+
+	return args.Error(0)
+}
+
+func (m *MockFileUseCase) RenameFile(ctx context.Context, fileID, name string) (*domain.File, error) {
+	args := m.Called(ctx, fileID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
 type MockUploadFileStream struct {
 	mock.Mock
 	requests []*proto.UploadFileRequest
 	response *proto.UploadFileResponse
+	recvErr  error
 }
 
 func (m *MockUploadFileStream) SendAndClose(response *proto.UploadFileResponse) error {
@@ -58,6 +270,9 @@ func (m *MockUploadFileStream) SendAndClose(response *proto.UploadFileResponse)
 
 func (m *MockUploadFileStream) Recv() (*proto.UploadFileRequest, error) {
 	if len(m.requests) == 0 {
+		if m.recvErr != nil {
+			return nil, m.recvErr
+		}
 		return nil, io.EOF
 	}
 	req := m.requests[0]
@@ -91,7 +306,7 @@ func (m *MockUploadFileStream) RecvMsg(interface{}) error {
 
 func Test_UploadFile_Success(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	testFileContent := "Hello, this is test file content!"
 	expectedFile := &domain.File{
@@ -104,14 +319,10 @@ func Test_UploadFile_Success(t *testing.T) {
 	mockUseCase.On(
 		"UploadFile",
 		mock.Anything,
-		"test.txt",
-		mock.AnythingOfType("*grpc.bytesReader")).
-		Return(expectedFile, nil).
-		Run(func(args mock.Arguments) {
-			reader := args.Get(2).(io.Reader)
-			data, _ := io.ReadAll(reader)
-			assert.Equal(t, testFileContent, string(data))
-		})
+		domain.FileUpload{Filename: "test.txt", ContentType: "text/plain"},
+		[]byte(testFileContent),
+		nil).
+		Return(expectedFile, nil)
 
 	mockStream := new(MockUploadFileStream)
 	mockStream.requests = []*proto.UploadFileRequest{
@@ -142,13 +353,13 @@ func Test_UploadFile_Success(t *testing.T) {
 	if mockStream.response != nil {
 		assert.Equal(t, "test-uuid", mockStream.response.Id)
 		assert.Equal(t, "test_123.txt", mockStream.response.Filename)
-		assert.Equal(t, uint32(len(testFileContent)), mockStream.response.Size)
+		assert.Equal(t, uint64(len(testFileContent)), mockStream.response.Size)
 	}
 }
 
 func Test_UploadFile_NoFileInfo(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	mockStream := new(MockUploadFileStream)
 	mockStream.requests = []*proto.UploadFileRequest{
@@ -175,13 +386,14 @@ func Test_UploadFile_NoFileInfo(t *testing.T) {
 
 func Test_UploadFile_UseCaseError(t *testing.T) {
 	mockUseCase := new(MockFileUseCase)
-	handler := NewFileHandler(mockUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
 
 	mockUseCase.On(
 		"UploadFile",
 		mock.Anything,
-		"test.txt",
-		mock.AnythingOfType("*grpc.bytesReader")).
+		domain.FileUpload{Filename: "test.txt", ContentType: "text/plain"},
+		mock.Anything,
+		nil).
 		Return(nil, assert.AnError)
 
 	mockStream := new(MockUploadFileStream)
@@ -212,3 +424,226 @@ func Test_UploadFile_UseCaseError(t *testing.T) {
 	mockUseCase.AssertExpectations(t)
 	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
 }
+
+func Test_UploadFile_ClientCancelled(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	cancelErr := status.Error(codes.Canceled, "context canceled")
+
+	mockUseCase.On(
+		"UploadFile",
+		mock.Anything,
+		domain.FileUpload{Filename: "test.txt"},
+		[]byte("partial"),
+		mock.MatchedBy(func(err error) bool { return errors.Is(err, cancelErr) })).
+		Return(nil, cancelErr)
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename: "test.txt",
+				},
+			},
+		},
+		{
+			Data: &proto.UploadFileRequest_ChunkData{
+				ChunkData: []byte("partial"),
+			},
+		},
+	}
+	mockStream.recvErr = cancelErr
+
+	err := handler.UploadFile(mockStream)
+	assert.Error(t, err)
+
+	mockUseCase.AssertExpectations(t)
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
+}
+
+func Test_UploadFile_DeclaredSizeTooLarge(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 10)
+
+	declaredSize := uint64(11)
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename:     "test.txt",
+					DeclaredSize: &declaredSize,
+				},
+			},
+		},
+	}
+
+	err := handler.UploadFile(mockStream)
+	assert.Error(t, err)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, grpcStatus.Code())
+
+	mockUseCase.AssertNotCalled(t, "UploadFile")
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
+}
+
+func Test_UploadFile_ExceedsMaxSize(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 10)
+
+	mockUseCase.On(
+		"UploadFile",
+		mock.Anything,
+		domain.FileUpload{Filename: "test.txt"},
+		[]byte("0123456789"),
+		mock.MatchedBy(func(err error) bool { return status.Code(err) == codes.ResourceExhausted })).
+		Return(nil, assert.AnError)
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename: "test.txt",
+				},
+			},
+		},
+		{
+			Data: &proto.UploadFileRequest_ChunkData{
+				ChunkData: []byte("0123456789"),
+			},
+		},
+		{
+			Data: &proto.UploadFileRequest_ChunkData{
+				ChunkData: []byte("x"),
+			},
+		},
+	}
+
+	err := handler.UploadFile(mockStream)
+	assert.Error(t, err)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, grpcStatus.Code())
+
+	mockUseCase.AssertExpectations(t)
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
+}
+
+func Test_UploadFile_ChecksumMismatch(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	expectedSHA256 := strings.Repeat("ab", 32)
+	mockUseCase.On(
+		"UploadFile",
+		mock.Anything,
+		domain.FileUpload{Filename: "test.txt", ExpectedSHA256: expectedSHA256},
+		[]byte("corrupted"),
+		nil).
+		Return(nil, fmt.Errorf("%w: expected sha256 %s", domain.ErrChecksumMismatch, expectedSHA256))
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename:       "test.txt",
+					ExpectedSha256: strings.ToUpper(expectedSHA256),
+				},
+			},
+		},
+		{
+			Data: &proto.UploadFileRequest_ChunkData{
+				ChunkData: []byte("corrupted"),
+			},
+		},
+	}
+
+	err := handler.UploadFile(mockStream)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.DataLoss, grpcStatus.Code())
+	mockUseCase.AssertExpectations(t)
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
+}
+
+func Test_UploadFile_QuotaExceeded(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	// The usecase stops reading once the upload outgrows the quota.
+	mockUseCase.On(
+		"UploadFile",
+		mock.Anything,
+		domain.FileUpload{Filename: "test.txt"},
+		[]byte("first chunksecond chunk"),
+		nil).
+		Return(nil, fmt.Errorf("%w for alice", domain.ErrQuotaExceeded))
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{Data: &proto.UploadFileRequest_Info{Info: &proto.FileInfo{Filename: "test.txt"}}},
+		{Data: &proto.UploadFileRequest_ChunkData{ChunkData: []byte("first chunk")}},
+		{Data: &proto.UploadFileRequest_ChunkData{ChunkData: []byte("second chunk")}},
+	}
+
+	err := handler.UploadFile(mockStream)
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadFileResponse"))
+}
+
+func Test_UploadFile_InvalidExpectedChecksum(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename:       "test.txt",
+					ExpectedSha256: "not-a-checksum",
+				},
+			},
+		},
+	}
+
+	err := handler.UploadFile(mockStream)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "UploadFile")
+}
+
+func Test_UploadFile_TooManyTags(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockStream := new(MockUploadFileStream)
+	mockStream.requests = []*proto.UploadFileRequest{
+		{
+			Data: &proto.UploadFileRequest_Info{
+				Info: &proto.FileInfo{
+					Filename: "test.txt",
+					Tags:     make([]string, maxTags+1),
+				},
+			},
+		},
+	}
+
+	err := handler.UploadFile(mockStream)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+	mockUseCase.AssertNotCalled(t, "UploadFile")
+}
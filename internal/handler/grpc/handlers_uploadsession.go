@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *fileHandler) InitiateUpload(ctx context.Context, req *proto.InitiateUploadRequest) (*proto.InitiateUploadResponse, error) {
+	fileInfo := req.GetInfo()
+	if fileInfo == nil || fileInfo.Filename == "" {
+		return nil, status.Error(codes.InvalidArgument, "file info is required")
+	}
+
+	upload, err := h.fileUploadFromInfo(fileInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := h.uploadSessionUseCase.InitiateUpload(ctx, upload)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &proto.InitiateUploadResponse{
+		UploadId:  session.ID,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}, nil
+}
+
+func (h *fileHandler) UploadChunk(stream proto.FileService_UploadChunkServer) error {
+	req, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	header := req.GetHeader()
+	if header == nil || header.UploadId == "" {
+		return status.Error(codes.InvalidArgument, "upload chunk header is required")
+	}
+
+	var session *domain.UploadSession
+	err = h.pipeUpload(
+		func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			chunk, ok := req.Data.(*proto.UploadChunkRequest_ChunkData)
+			if !ok {
+				return nil, status.Error(codes.InvalidArgument, "header must be sent only once, before any chunk")
+			}
+
+			return chunk.ChunkData, nil
+		},
+		header.Offset,
+		func(r io.Reader) error {
+			session, err = h.uploadSessionUseCase.UploadChunk(stream.Context(), header.UploadId, int64(header.Offset), r)
+			return err
+		},
+	)
+	if err != nil {
+		return toStatusError(err)
+	}
+
+	return stream.SendAndClose(&proto.UploadChunkResponse{
+		UploadId:      session.ID,
+		BytesReceived: uint64(session.BytesReceived),
+	})
+}
+
+func (h *fileHandler) GetUploadStatus(ctx context.Context, req *proto.GetUploadStatusRequest) (*proto.GetUploadStatusResponse, error) {
+	if req.UploadId == "" {
+		return nil, status.Error(codes.InvalidArgument, "upload id is required")
+	}
+
+	session, err := h.uploadSessionUseCase.GetUploadStatus(ctx, req.UploadId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &proto.GetUploadStatusResponse{
+		UploadId:      session.ID,
+		Filename:      session.Filename,
+		BytesReceived: uint64(session.BytesReceived),
+		ExpiresAt:     timestamppb.New(session.ExpiresAt),
+	}
+	if session.DeclaredSize != nil {
+		declaredSize := uint64(*session.DeclaredSize)
+		resp.DeclaredSize = &declaredSize
+	}
+
+	return resp, nil
+}
+
+func (h *fileHandler) CompleteUpload(ctx context.Context, req *proto.CompleteUploadRequest) (*proto.UploadFileResponse, error) {
+	if req.UploadId == "" {
+		return nil, status.Error(codes.InvalidArgument, "upload id is required")
+	}
+
+	file, err := h.uploadSessionUseCase.CompleteUpload(ctx, req.UploadId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toUploadFileResponse(file), nil
+}
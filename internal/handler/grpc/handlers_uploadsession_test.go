@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type MockUploadSessionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUploadSessionUseCase) InitiateUpload(ctx context.Context, upload domain.FileUpload) (*domain.UploadSession, error) {
+	args := m.Called(ctx, upload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.UploadSession), args.Error(1)
+}
+
+// UploadChunk reads data to the end before recording the call, like
+// MockFileUseCase.UploadFile.
+func (m *MockUploadSessionUseCase) UploadChunk(ctx context.Context, uploadID string, offset int64, data io.Reader) (*domain.UploadSession, error) {
+	content, err := io.ReadAll(data)
+	args := m.Called(ctx, uploadID, offset, content, err)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.UploadSession), args.Error(1)
+}
+
+func (m *MockUploadSessionUseCase) GetUploadStatus(ctx context.Context, uploadID string) (*domain.UploadSession, error) {
+	args := m.Called(ctx, uploadID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.UploadSession), args.Error(1)
+}
+
+func (m *MockUploadSessionUseCase) CompleteUpload(ctx context.Context, uploadID string) (*domain.File, error) {
+	args := m.Called(ctx, uploadID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockUploadSessionUseCase) ExpireSessions(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+
+	return args.Int(0), args.Error(1)
+}
+
+type MockUploadChunkStream struct {
+	mock.Mock
+	requests []*proto.UploadChunkRequest
+	response *proto.UploadChunkResponse
+}
+
+func (m *MockUploadChunkStream) SendAndClose(response *proto.UploadChunkResponse) error {
+	m.response = response
+	args := m.Called(response)
+
+	return args.Error(0)
+}
+
+func (m *MockUploadChunkStream) Recv() (*proto.UploadChunkRequest, error) {
+	if len(m.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := m.requests[0]
+	m.requests = m.requests[1:]
+
+	return req, nil
+}
+
+func (m *MockUploadChunkStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (m *MockUploadChunkStream) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (m *MockUploadChunkStream) SetTrailer(metadata.MD) {
+}
+
+func (m *MockUploadChunkStream) Context() context.Context {
+	return context.Background()
+}
+
+func (m *MockUploadChunkStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func (m *MockUploadChunkStream) RecvMsg(interface{}) error {
+	return nil
+}
+
+func Test_InitiateUpload_Success(t *testing.T) {
+	mockSessions := new(MockUploadSessionUseCase)
+	handler := NewFileHandler(nil, mockSessions, 0)
+
+	expiresAt := time.Now().Add(time.Hour)
+	declaredSize := uint64(42)
+	mockSessions.On(
+		"InitiateUpload",
+		mock.Anything,
+		mock.MatchedBy(func(upload domain.FileUpload) bool {
+			return upload.Filename == "big.bin" && upload.DeclaredSize != nil && *upload.DeclaredSize == 42
+		})).
+		Return(&domain.UploadSession{ID: "upload-1", ExpiresAt: expiresAt}, nil)
+
+	resp, err := handler.InitiateUpload(context.Background(), &proto.InitiateUploadRequest{
+		Info: &proto.FileInfo{
+			Filename:     "big.bin",
+			DeclaredSize: &declaredSize,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "upload-1", resp.UploadId)
+	assert.True(t, expiresAt.Equal(resp.ExpiresAt.AsTime()))
+	mockSessions.AssertExpectations(t)
+}
+
+func Test_UploadChunk_ResumeAtOffset(t *testing.T) {
+	mockSessions := new(MockUploadSessionUseCase)
+	handler := NewFileHandler(nil, mockSessions, 0)
+
+	mockSessions.On(
+		"UploadChunk",
+		mock.Anything,
+		"upload-1",
+		int64(5),
+		[]byte("world"),
+		nil).
+		Return(&domain.UploadSession{ID: "upload-1", BytesReceived: 10}, nil)
+
+	mockStream := new(MockUploadChunkStream)
+	mockStream.requests = []*proto.UploadChunkRequest{
+		{
+			Data: &proto.UploadChunkRequest_Header{
+				Header: &proto.UploadChunkHeader{UploadId: "upload-1", Offset: 5},
+			},
+		},
+		{
+			Data: &proto.UploadChunkRequest_ChunkData{ChunkData: []byte("world")},
+		},
+	}
+	mockStream.On("SendAndClose", mock.AnythingOfType("*proto.UploadChunkResponse")).Return(nil)
+
+	err := handler.UploadChunk(mockStream)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), mockStream.response.BytesReceived)
+	mockSessions.AssertExpectations(t)
+}
+
+func Test_UploadChunk_InvalidOffset(t *testing.T) {
+	mockSessions := new(MockUploadSessionUseCase)
+	handler := NewFileHandler(nil, mockSessions, 0)
+
+	mockSessions.On(
+		"UploadChunk",
+		mock.Anything,
+		"upload-1",
+		int64(100),
+		[]byte("data"),
+		nil).
+		Return(nil, domain.ErrInvalidOffset)
+
+	mockStream := new(MockUploadChunkStream)
+	mockStream.requests = []*proto.UploadChunkRequest{
+		{
+			Data: &proto.UploadChunkRequest_Header{
+				Header: &proto.UploadChunkHeader{UploadId: "upload-1", Offset: 100},
+			},
+		},
+		{
+			Data: &proto.UploadChunkRequest_ChunkData{ChunkData: []byte("data")},
+		},
+	}
+
+	err := handler.UploadChunk(mockStream)
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.OutOfRange, grpcStatus.Code())
+	mockStream.AssertNotCalled(t, "SendAndClose", mock.AnythingOfType("*proto.UploadChunkResponse"))
+}
+
+func Test_CompleteUpload_Incomplete(t *testing.T) {
+	mockSessions := new(MockUploadSessionUseCase)
+	handler := NewFileHandler(nil, mockSessions, 0)
+
+	mockSessions.On("CompleteUpload", mock.Anything, "upload-1").Return(nil, domain.ErrUploadIncomplete)
+
+	_, err := handler.CompleteUpload(context.Background(), &proto.CompleteUploadRequest{UploadId: "upload-1"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, grpcStatus.Code())
+	mockSessions.AssertExpectations(t)
+}
+
+func Test_GetUploadStatus_NotFound(t *testing.T) {
+	mockSessions := new(MockUploadSessionUseCase)
+	handler := NewFileHandler(nil, mockSessions, 0)
+
+	mockSessions.On("GetUploadStatus", mock.Anything, "missing").Return(nil, domain.ErrNotFound)
+
+	_, err := handler.GetUploadStatus(context.Background(), &proto.GetUploadStatusRequest{UploadId: "missing"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+	mockSessions.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/api/proto"
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_ListVersions_NewestFirst(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("ListVersions", mock.Anything, "report.pdf").Return([]domain.File{
+		{ID: "v2", Filename: "report.pdf", Version: 2},
+		{ID: "v1", Filename: "report.pdf", Version: 1},
+	}, nil)
+
+	resp, err := handler.ListVersions(context.Background(), &proto.ListVersionsRequest{Filename: "report.pdf"})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Versions, 2)
+	assert.Equal(t, "v2", resp.Versions[0].Id)
+	assert.Equal(t, uint32(2), resp.Versions[0].Version)
+	assert.Equal(t, uint32(1), resp.Versions[1].Version)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_ListVersions_NotFound(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("ListVersions", mock.Anything, "missing.txt").Return(nil, domain.ErrNotFound)
+
+	_, err := handler.ListVersions(context.Background(), &proto.ListVersionsRequest{Filename: "missing.txt"})
+
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, grpcStatus.Code())
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_RestoreVersion_ReturnsNewVersion(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("RestoreVersion", mock.Anything, "v1").Return(&domain.File{
+		ID:       "v3",
+		Filename: "report.pdf",
+		Version:  3,
+	}, nil)
+
+	resp, err := handler.RestoreVersion(context.Background(), &proto.RestoreVersionRequest{FileId: "v1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", resp.Id)
+	assert.Equal(t, uint32(3), resp.Version)
+	mockUseCase.AssertExpectations(t)
+}
+
+func Test_SetVersionRetention(t *testing.T) {
+	mockUseCase := new(MockFileUseCase)
+	handler := NewFileHandler(mockUseCase, nil, 0)
+
+	mockUseCase.On("SetVersionRetention", mock.Anything, "report.pdf", 5).Return(nil)
+
+	_, err := handler.SetVersionRetention(context.Background(), &proto.SetVersionRetentionRequest{
+		Filename:     "report.pdf",
+		KeepVersions: 5,
+	})
+
+	assert.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+
+	_, err = handler.SetVersionRetention(context.Background(), &proto.SetVersionRetentionRequest{})
+	grpcStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, grpcStatus.Code())
+}
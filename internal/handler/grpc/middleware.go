@@ -30,12 +30,24 @@ func (l *ConcurrencyLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		if info.FullMethod == "/file_service.FileService/ListFiles" {
+		switch info.FullMethod {
+		case "/file_service.FileService/ListFiles",
+			"/file_service.FileService/ListVersions",
+			"/file_service.FileService/ListTrash",
+			"/file_service.FileService/ListFolder",
+			"/file_service.FileService/SearchFiles":
 			if !l.listSem.TryAcquire(1) {
 				return nil, status.Error(codes.ResourceExhausted,
 					"too many concurrent list requests (max 100)")
 			}
 			defer l.listSem.Release(1)
+		case "/file_service.FileService/CompleteUpload",
+			"/file_service.FileService/RestoreVersion":
+			if !l.uploadSem.TryAcquire(1) {
+				return nil, status.Error(codes.ResourceExhausted,
+					"too many concurrent requests (max 10)")
+			}
+			defer l.uploadSem.Release(1)
 		}
 
 		return handler(ctx, req)
@@ -52,7 +64,8 @@ func (l *ConcurrencyLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
 		var sem *semaphore.Weighted
 
 		switch info.FullMethod {
-		case "/file_service.FileService/UploadFile":
+		case "/file_service.FileService/UploadFile",
+			"/file_service.FileService/UploadChunk":
 			sem = l.uploadSem
 		case "/file_service.FileService/DownloadFile":
 
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+// pageToken is the position of the last file of a page and the order it
+// was listed in. Clients treat the encoded token as opaque.
+type pageToken struct {
+	SortBy    domain.SortField `json:"s,omitempty"`
+	Ascending bool             `json:"a,omitempty"`
+	CreatedAt time.Time        `json:"t"`
+	UpdatedAt time.Time        `json:"u"`
+	Filename  string           `json:"n,omitempty"`
+	Size      int64            `json:"z,omitempty"`
+	ID        string           `json:"id"`
+}
+
+func encodePageToken(cursor *domain.FileCursor, sortBy domain.SortField, ascending bool) (string, error) {
+	data, err := json.Marshal(pageToken{
+		SortBy:    sortBy,
+		Ascending: ascending,
+		CreatedAt: cursor.CreatedAt,
+		UpdatedAt: cursor.UpdatedAt,
+		Filename:  cursor.Filename,
+		Size:      cursor.Size,
+		ID:        cursor.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodePageToken returns the cursor in token, which must have been issued
+// for the same order.
+func decodePageToken(token string, sortBy domain.SortField, ascending bool) (*domain.FileCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+
+	var decoded pageToken
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.ID == "" {
+		return nil, errInvalidPageToken
+	}
+	// Tokens issued before listings could be sorted have no order.
+	if decoded.SortBy == "" {
+		decoded.SortBy = domain.SortByCreatedAt
+	}
+	if decoded.SortBy != sortBy || decoded.Ascending != ascending {
+		return nil, errors.New("page token was issued for a different sort order")
+	}
+
+	return &domain.FileCursor{
+		CreatedAt: decoded.CreatedAt,
+		UpdatedAt: decoded.UpdatedAt,
+		Filename:  decoded.Filename,
+		Size:      decoded.Size,
+		ID:        decoded.ID,
+	}, nil
+}
+
+// folderPageToken is the position of the last entry of a folder listing
+// page and the folder it lists.
+type folderPageToken struct {
+	Folder string `json:"d,omitempty"`
+	Path   string `json:"p"`
+	FileID string `json:"i,omitempty"`
+	IsFile bool   `json:"f,omitempty"`
+}
+
+func encodeFolderPageToken(cursor *domain.FolderCursor, folder string) (string, error) {
+	data, err := json.Marshal(folderPageToken{
+		Folder: folder,
+		Path:   cursor.Path,
+		FileID: cursor.FileID,
+		IsFile: cursor.IsFile,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeFolderPageToken returns the cursor in token, which must have been
+// issued for the same folder.
+func decodeFolderPageToken(token string, folder string) (*domain.FolderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+
+	var decoded folderPageToken
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Path == "" {
+		return nil, errInvalidPageToken
+	}
+	if decoded.Folder != folder {
+		return nil, errors.New("page token was issued for a different folder")
+	}
+
+	return &domain.FolderCursor{
+		Path:   decoded.Path,
+		FileID: decoded.FileID,
+		IsFile: decoded.IsFile,
+	}, nil
+}
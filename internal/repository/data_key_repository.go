@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+type postgresDataKeyRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDataKeyRepository(db *sql.DB) DataKeyRepository {
+	return &postgresDataKeyRepository{
+		db: db,
+	}
+}
+
+// ListStale returns data keys wrapped with a master key other than
+// activeKeyID. Files sharing a deduplicated blob are covered by the blob's
+// key and not listed on their own.
+func (r *postgresDataKeyRepository) ListStale(ctx context.Context, activeKeyID string, limit int) ([]domain.WrappedDataKey, error) {
+	query := `SELECT id, '', key_id, wrapped_key FROM files
+				WHERE key_id IS NOT NULL AND key_id <> $1 AND blob_sha256 IS NULL
+				UNION ALL
+				SELECT '', sha256, key_id, wrapped_key FROM blobs
+				WHERE key_id IS NOT NULL AND key_id <> $1
+				LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, activeKeyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]domain.WrappedDataKey, 0)
+	for rows.Next() {
+		var key domain.WrappedDataKey
+		if err := rows.Scan(&key.FileID, &key.BlobSHA256, &key.KeyID, &key.WrappedKey); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Rewrap replaces a data key's wrapping, provided it is still wrapped as in
+// old. A blob's key is replaced on every file referencing the blob too.
+func (r *postgresDataKeyRepository) Rewrap(ctx context.Context, old domain.WrappedDataKey, keyID string, wrappedKey []byte) error {
+	if old.BlobSHA256 == "" {
+		query := `UPDATE files SET key_id = $1, wrapped_key = $2 WHERE id = $3 AND key_id = $4`
+		result, err := r.db.ExecContext(ctx, query, keyID, wrappedKey, old.FileID, old.KeyID)
+		if err != nil {
+			return err
+		}
+		return requireAffected(result)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE blobs SET key_id = $1, wrapped_key = $2 WHERE sha256 = $3 AND key_id = $4`
+	result, err := tx.ExecContext(ctx, query, keyID, wrappedKey, old.BlobSHA256, old.KeyID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+
+	query = `UPDATE files SET key_id = $1, wrapped_key = $2 WHERE blob_sha256 = $3`
+	if _, err := tx.ExecContext(ctx, query, keyID, wrappedKey, old.BlobSHA256); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// GetPermission returns the highest permission on the file filename of
+// ownerID granted to the accessor or one of its groups, or PermissionNone.
+func (r *postgresFileRepository) GetPermission(ctx context.Context, ownerID, filename string, accessor domain.Accessor) (domain.Permission, error) {
+	query := `SELECT permission FROM file_acl
+				WHERE owner_id = $1 AND filename = $2 AND (
+					(grantee_type = 'user' AND grantee_id = $3)
+					OR (grantee_type = 'group' AND grantee_id = ANY($4)))
+				ORDER BY permission = 'write' DESC
+				LIMIT 1`
+	var permission domain.Permission
+	err := r.db.QueryRowContext(ctx, query, ownerID, filename, accessor.ID, pq.Array(nonNilStrings(accessor.Groups))).Scan(&permission)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PermissionNone, nil
+	}
+
+	return permission, err
+}
+
+// PutGrant shares a file, replacing the permission of an existing grant to
+// the same grantee.
+func (r *postgresFileRepository) PutGrant(ctx context.Context, grant *domain.Grant) error {
+	query := `INSERT INTO file_acl (owner_id, filename, grantee_type, grantee_id, permission) VALUES($1, $2, $3, $4, $5)
+				ON CONFLICT (owner_id, filename, grantee_type, grantee_id) DO UPDATE SET permission = EXCLUDED.permission
+				RETURNING created_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		grant.OwnerID,
+		grant.Filename,
+		grant.Grantee.Type,
+		grant.Grantee.ID,
+		grant.Permission,
+	).Scan(&grant.CreatedAt)
+}
+
+func (r *postgresFileRepository) DeleteGrant(ctx context.Context, ownerID, filename string, grantee domain.Grantee) error {
+	query := `DELETE FROM file_acl WHERE owner_id = $1 AND filename = $2 AND grantee_type = $3 AND grantee_id = $4`
+	result, err := r.db.ExecContext(ctx, query, ownerID, filename, grantee.Type, grantee.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return fmt.Errorf("grant to %s %s: %w", grantee.Type, grantee.ID, err)
+	}
+
+	return nil
+}
+
+func (r *postgresFileRepository) ListGrants(ctx context.Context, ownerID, filename string) ([]domain.Grant, error) {
+	query := `SELECT grantee_type, grantee_id, permission, created_at FROM file_acl
+				WHERE owner_id = $1 AND filename = $2
+				ORDER BY grantee_type, grantee_id`
+	rows, err := r.db.QueryContext(ctx, query, ownerID, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]domain.Grant, 0)
+	for rows.Next() {
+		grant := domain.Grant{OwnerID: ownerID, Filename: filename}
+		if err := rows.Scan(&grant.Grantee.Type, &grant.Grantee.ID, &grant.Permission, &grant.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// deleteOrphanedGrants deletes the grants on the file filename of ownerID
+// once no version of it is left, trashed ones included, so they do not
+// share a file the owner uploads under the name later.
+func deleteOrphanedGrants(ctx context.Context, tx *sql.Tx, ownerID, filename string) error {
+	query := `DELETE FROM file_acl WHERE owner_id = $1 AND filename = $2
+				AND NOT EXISTS (SELECT 1 FROM files WHERE owner_key = $1 AND filename = $2)`
+	_, err := tx.ExecContext(ctx, query, ownerID, filename)
+
+	return err
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// CreateFolder creates the folder at path along with any missing parent
+// folders, owned by ownerID. Only files of ownerID, "" being the files
+// without an owner, can be in the way.
+func (r *postgresFileRepository) CreateFolder(ctx context.Context, ownerID, path string) (*domain.Folder, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockPath(ctx, tx, path); err != nil {
+		return nil, err
+	}
+	if exists, err := folderExists(ctx, tx, path); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("folder %s: %w", path, domain.ErrAlreadyExists)
+	}
+	if err := ensureFolders(ctx, tx, ownerID, path); err != nil {
+		return nil, err
+	}
+
+	folder := &domain.Folder{Path: path}
+	query := `SELECT owner_id, created_at FROM folders WHERE path = $1`
+	var owner sql.NullString
+	if err := tx.QueryRowContext(ctx, query, path).Scan(&owner, &folder.CreatedAt); err != nil {
+		return nil, err
+	}
+	folder.OwnerID = owner.String
+
+	return folder, tx.Commit()
+}
+
+// ListFolder returns up to limit direct children of the folder at path,
+// starting after the given one. Subfolders come first, so the files are
+// only queried once they are exhausted. Files are restricted to those
+// visible to the accessor if it is not nil; folders are visible to all.
+func (r *postgresFileRepository) ListFolder(ctx context.Context, path string, visibleTo *domain.Accessor, after *domain.FolderCursor, limit int) (*domain.FolderListing, error) {
+	if path != "" {
+		if exists, err := folderExists(ctx, r.db, path); err != nil {
+			return nil, err
+		} else if !exists {
+			return nil, fmt.Errorf("folder %s: %w", path, domain.ErrNotFound)
+		}
+	}
+
+	listing := &domain.FolderListing{
+		Folders: make([]domain.Folder, 0),
+		Files:   make([]domain.File, 0),
+	}
+	if after == nil || !after.IsFile {
+		afterPath := ""
+		if after != nil {
+			afterPath = after.Path
+		}
+
+		// One extra row is fetched to tell whether another page follows.
+		folders, err := r.queryFolders(ctx, path, afterPath, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(folders) > limit {
+			listing.Folders = folders[:limit]
+			listing.Next = &domain.FolderCursor{Path: folders[limit-1].Path}
+			return listing, nil
+		}
+		listing.Folders = folders
+	}
+
+	// Files of different owners can share a name, so ties are broken by
+	// id.
+	afterFilename, afterID := "", ""
+	if after != nil && after.IsFile {
+		afterFilename, afterID = after.Path, after.FileID
+	}
+	remaining := limit - len(listing.Folders)
+	args := queryArgs{path, afterFilename, afterID}
+	conditions := []string{`parent_path = $1`, `(filename, id) > ($2, $3)`, latestVersionCondition}
+	if visibleTo != nil {
+		conditions = append(conditions, accessCondition(visibleTo, &args))
+	}
+	query := `SELECT ` + fileColumns + ` FROM files
+				WHERE ` + strings.Join(conditions, " AND ") + `
+				ORDER BY filename, id
+				LIMIT ` + args.add(remaining+1)
+	files, err := r.queryFiles(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) <= remaining {
+		listing.Files = files
+		return listing, nil
+	}
+
+	listing.Files = files[:remaining]
+	if remaining > 0 {
+		last := files[remaining-1]
+		listing.Next = &domain.FolderCursor{Path: last.Filename, FileID: last.ID, IsFile: true}
+	} else {
+		listing.Next = &domain.FolderCursor{Path: listing.Folders[len(listing.Folders)-1].Path}
+	}
+
+	return listing, nil
+}
+
+func (r *postgresFileRepository) queryFolders(ctx context.Context, parentPath, afterPath string, limit int) ([]domain.Folder, error) {
+	query := `SELECT path, owner_id, created_at FROM folders
+				WHERE parent_path = $1 AND path > $2
+				ORDER BY path
+				LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, parentPath, afterPath, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := make([]domain.Folder, 0)
+	for rows.Next() {
+		var folder domain.Folder
+		var owner sql.NullString
+		if err := rows.Scan(&folder.Path, &owner, &folder.CreatedAt); err != nil {
+			return nil, err
+		}
+		folder.OwnerID = owner.String
+
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
+
+// Rename gives all versions of the file filename of ownerID the new name,
+// which must be in an existing folder that can hold files of ownerID and
+// not taken by a folder or another file of ownerID. Only rows change; blobs are keyed by file id and stay
+// where they are.
+func (r *postgresFileRepository) Rename(ctx context.Context, ownerID, filename, newFilename string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Both names are locked deepest first like in lockPath, and by name
+	// at the same depth, so every transaction takes locks in one order.
+	names := []string{filename, newFilename}
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := strings.Count(names[i], "/"), strings.Count(names[j], "/")
+		if di != dj {
+			return di > dj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if err := lockFilename(ctx, tx, name); err != nil {
+			return err
+		}
+	}
+
+	if folder := domain.ParentPath(newFilename); folder != "" && folder != domain.ParentPath(filename) {
+		var owner sql.NullString
+		err := tx.QueryRowContext(ctx, `SELECT owner_id FROM folders WHERE path = $1`, folder).Scan(&owner)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("folder %s: %w", folder, domain.ErrNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if err := checkFolderOwner(ownerID, folder, owner); err != nil {
+			return err
+		}
+	}
+	// Rows waiting to be deleted count too, since their versions would
+	// collide with the moved ones.
+	var taken bool
+	query := `SELECT EXISTS(SELECT 1 FROM files WHERE owner_key = $1 AND filename = $2)
+				OR EXISTS(SELECT 1 FROM folders WHERE path = $2)`
+	if err := tx.QueryRowContext(ctx, query, ownerID, newFilename).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%s: %w", newFilename, domain.ErrAlreadyExists)
+	}
+
+	query = `UPDATE files SET filename = $3, updated_at = NOW() WHERE owner_key = $1 AND filename = $2`
+	result, err := tx.ExecContext(ctx, query, ownerID, filename, newFilename)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+
+	// The retention setting and grants move with the file, replacing any
+	// left over from files deleted under the new name.
+	for _, table := range []string{"version_retention", "file_acl"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE owner_id = $1 AND filename = $2`, ownerID, newFilename); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET filename = $3 WHERE owner_id = $1 AND filename = $2`, ownerID, filename, newFilename); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// lockPath takes the filename lock of path and of every folder it is in,
+// deepest first, so that a file and a folder are never created under the
+// same path. Every caller locks in this order.
+func lockPath(ctx context.Context, tx *sql.Tx, path string) error {
+	for p := path; p != ""; p = domain.ParentPath(p) {
+		if err := lockFilename(ctx, tx, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockFilename serializes version numbering for a filename until the
+// transaction ends. Taking it twice in one transaction is harmless.
+func lockFilename(ctx context.Context, tx *sql.Tx, filename string) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, filename)
+
+	return err
+}
+
+// ensureFolders creates the folder at path and its parents if they do not
+// exist, owned by ownerID, and checks that the folder can hold files and
+// folders of ownerID. Only files of ownerID can be in the way; files of
+// other owners are not even looked at, so whether they exist is never
+// revealed. The caller must hold the locks of the folders.
+func ensureFolders(ctx context.Context, tx *sql.Tx, ownerID, path string) error {
+	var paths []string
+	for p := path; p != ""; p = domain.ParentPath(p) {
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// The deepest folder that exists is path itself or the one new folders
+	// are created in.
+	var existing string
+	var owner sql.NullString
+	query := `SELECT path, owner_id FROM folders WHERE path = ANY($1) ORDER BY length(path) DESC LIMIT 1`
+	err := tx.QueryRowContext(ctx, query, pq.Array(paths)).Scan(&existing, &owner)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	missing := paths
+	for i, p := range paths {
+		if p == existing {
+			missing = paths[:i]
+			break
+		}
+	}
+	if err := checkFolderOwner(ownerID, existing, owner); err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var conflict sql.NullString
+	query = `SELECT filename FROM files WHERE owner_key = $1 AND filename = ANY($2) AND NOT pending_delete LIMIT 1`
+	err = tx.QueryRowContext(ctx, query, ownerID, pq.Array(missing)).Scan(&conflict)
+	if err == nil {
+		return fmt.Errorf("file %s: %w", conflict.String, domain.ErrAlreadyExists)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	parentPaths := make([]string, len(missing))
+	for i, p := range missing {
+		parentPaths[i] = domain.ParentPath(p)
+	}
+	query = `INSERT INTO folders (path, parent_path, owner_id)
+				SELECT path, parent_path, $3 FROM unnest($1::text[], $2::text[]) AS new(path, parent_path)
+				ON CONFLICT (path) DO NOTHING`
+	_, err = tx.ExecContext(ctx, query, pq.Array(missing), pq.Array(parentPaths), sql.NullString{String: ownerID, Valid: ownerID != ""})
+
+	return err
+}
+
+// checkFolderOwner checks that the folder at path, owned by owner, can hold
+// files and folders of ownerID. Folders are visible to all, so folders with
+// an owner only hold those of their owner. Entries without an owner come
+// from callers that are not restricted.
+func checkFolderOwner(ownerID, path string, owner sql.NullString) error {
+	if ownerID != "" && owner.Valid && owner.String != ownerID {
+		return fmt.Errorf("%w: folder %s belongs to another owner", domain.ErrPermissionDenied, path)
+	}
+
+	return nil
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func folderExists(ctx context.Context, q queryRower, path string) (bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM folders WHERE path = $1)`, path).Scan(&exists)
+
+	return exists, err
+}
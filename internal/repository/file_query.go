@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+var sortColumns = map[domain.SortField]string{
+	"":                     "created_at",
+	domain.SortByCreatedAt: "created_at",
+	domain.SortByUpdatedAt: "updated_at",
+	domain.SortByFilename:  "filename",
+	domain.SortBySize:      "size",
+}
+
+// queryArgs collects query arguments and hands out their placeholders, so
+// conditions never have values formatted into them.
+type queryArgs []any
+
+func (a *queryArgs) add(value any) string {
+	*a = append(*a, value)
+
+	return "$" + strconv.Itoa(len(*a))
+}
+
+func filterConditions(filter domain.FileFilter, args *queryArgs) []string {
+	var conditions []string
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, `filename LIKE `+args.add(escapeLike(filter.NamePrefix)+"%"))
+	}
+	if filter.NameContains != "" {
+		conditions = append(conditions, `filename ILIKE `+args.add("%"+escapeLike(filter.NameContains)+"%"))
+	}
+	if filter.ContentType != "" {
+		contentType := strings.ToLower(filter.ContentType)
+		if mainType, ok := strings.CutSuffix(contentType, "/*"); ok {
+			conditions = append(conditions, `content_type LIKE `+args.add(escapeLike(mainType)+"/%"))
+		} else {
+			conditions = append(conditions, `content_type = `+args.add(contentType))
+		}
+	}
+	if len(filter.Labels) > 0 {
+		// Marshaling a map of strings cannot fail.
+		labels, _ := json.Marshal(filter.Labels)
+		conditions = append(conditions, `labels @> `+args.add(string(labels))+`::jsonb`)
+	}
+	if filter.MinSize != nil {
+		conditions = append(conditions, `size >= `+args.add(*filter.MinSize))
+	}
+	if filter.MaxSize != nil {
+		conditions = append(conditions, `size <= `+args.add(*filter.MaxSize))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, `created_at >= `+args.add(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, `created_at < `+args.add(*filter.CreatedBefore))
+	}
+	if filter.UpdatedAfter != nil {
+		conditions = append(conditions, `updated_at >= `+args.add(*filter.UpdatedAfter))
+	}
+	if filter.UpdatedBefore != nil {
+		conditions = append(conditions, `updated_at < `+args.add(*filter.UpdatedBefore))
+	}
+	if filter.VisibleTo != nil {
+		conditions = append(conditions, accessCondition(filter.VisibleTo, args))
+	}
+
+	return conditions
+}
+
+// accessCondition matches the files accessor can read: unowned files, its
+// own, and those shared with it or one of its groups by their owner.
+func accessCondition(accessor *domain.Accessor, args *queryArgs) string {
+	id := args.add(accessor.ID)
+	groups := args.add(pq.Array(nonNilStrings(accessor.Groups)))
+
+	return `(owner_id IS NULL OR owner_id = ` + id + ` OR EXISTS (
+					SELECT 1 FROM file_acl
+					WHERE file_acl.owner_id = files.owner_key AND file_acl.filename = files.filename AND (
+						(file_acl.grantee_type = 'user' AND file_acl.grantee_id = ` + id + `)
+						OR (file_acl.grantee_type = 'group' AND file_acl.grantee_id = ANY(` + groups + `)))))`
+}
+
+// cursorCondition matches the files after cursor in the given order. Ties
+// on the sort column are broken by id.
+func cursorCondition(sortBy domain.SortField, ascending bool, cursor *domain.FileCursor, args *queryArgs) string {
+	var value any
+	switch sortBy {
+	case domain.SortByUpdatedAt:
+		value = cursor.UpdatedAt
+	case domain.SortByFilename:
+		value = cursor.Filename
+	case domain.SortBySize:
+		value = cursor.Size
+	default:
+		value = cursor.CreatedAt
+	}
+
+	operator := "<"
+	if ascending {
+		operator = ">"
+	}
+
+	return fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumns[sortBy], operator, args.add(value), args.add(cursor.ID))
+}
+
+// escapeLike escapes the LIKE wildcards in s, so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FilterConditions_Parameterized(t *testing.T) {
+	maxSize := int64(4096)
+	createdBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var args queryArgs
+
+	conditions := filterConditions(domain.FileFilter{
+		NamePrefix:    "50%_off",
+		NameContains:  `a\b`,
+		ContentType:   "Image/*",
+		MaxSize:       &maxSize,
+		CreatedBefore: &createdBefore,
+	}, &args)
+
+	assert.Equal(t, []string{
+		`filename LIKE $1`,
+		`filename ILIKE $2`,
+		`content_type LIKE $3`,
+		`size <= $4`,
+		`created_at < $5`,
+	}, conditions)
+	assert.Equal(t, queryArgs{`50\%\_off%`, `%a\\b%`, "image/%", maxSize, createdBefore}, args)
+}
+
+func Test_FilterConditions_ExactContentType(t *testing.T) {
+	var args queryArgs
+
+	conditions := filterConditions(domain.FileFilter{ContentType: "text/plain"}, &args)
+
+	assert.Equal(t, []string{`content_type = $1`}, conditions)
+	assert.Equal(t, queryArgs{"text/plain"}, args)
+}
+
+func Test_CursorCondition(t *testing.T) {
+	cursor := &domain.FileCursor{Filename: "b.txt", Size: 10, ID: "id-1"}
+	args := queryArgs{"earlier"}
+
+	assert.Equal(t, `(filename, id) > ($2, $3)`, cursorCondition(domain.SortByFilename, true, cursor, &args))
+	assert.Equal(t, `(size, id) < ($4, $5)`, cursorCondition(domain.SortBySize, false, cursor, &args))
+	assert.Equal(t, queryArgs{"earlier", "b.txt", "id-1", int64(10), "id-1"}, args)
+}
+
+func Test_FilterConditions_Labels(t *testing.T) {
+	var args queryArgs
+
+	conditions := filterConditions(domain.FileFilter{Labels: map[string]string{"project": "alpha"}}, &args)
+
+	assert.Equal(t, []string{`labels @> $1::jsonb`}, conditions)
+	assert.Equal(t, queryArgs{`{"project":"alpha"}`}, args)
+}
+
+func Test_FilterConditions_VisibleTo(t *testing.T) {
+	var args queryArgs
+
+	conditions := filterConditions(domain.FileFilter{VisibleTo: &domain.Accessor{ID: "bob"}}, &args)
+
+	assert.Len(t, conditions, 1)
+	assert.Contains(t, conditions[0], `owner_id = $1`)
+	assert.Contains(t, conditions[0], `file_acl.owner_id = files.owner_key AND file_acl.filename = files.filename`)
+	assert.Contains(t, conditions[0], `file_acl.grantee_id = ANY($2)`)
+	assert.Equal(t, queryArgs{"bob", pq.Array([]string{})}, args)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+// GetUsage returns what ownerID stores and its quota. Owners that never
+// stored anything and have no quota get zero usage.
+func (r *postgresFileRepository) GetUsage(ctx context.Context, ownerID string) (*domain.Usage, error) {
+	query := `SELECT used_bytes, used_files, max_bytes, max_files FROM quotas WHERE owner_id = $1`
+
+	usage := &domain.Usage{OwnerID: ownerID}
+	err := r.db.QueryRowContext(ctx, query, ownerID).Scan(&usage.Bytes, &usage.Files, &usage.MaxBytes, &usage.MaxFiles)
+	if errors.Is(err, sql.ErrNoRows) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// SetQuota replaces the quota of ownerID and returns its usage. Files it
+// already stores are kept even if they exceed the new quota.
+func (r *postgresFileRepository) SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error) {
+	query := `INSERT INTO quotas (owner_id, max_bytes, max_files) VALUES($1, $2, $3)
+				ON CONFLICT (owner_id) DO UPDATE SET max_bytes = EXCLUDED.max_bytes, max_files = EXCLUDED.max_files
+				RETURNING used_bytes, used_files, max_bytes, max_files`
+
+	usage := &domain.Usage{OwnerID: ownerID}
+	err := r.db.QueryRowContext(ctx, query, ownerID, quota.MaxBytes, quota.MaxFiles).
+		Scan(&usage.Bytes, &usage.Files, &usage.MaxBytes, &usage.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// chargeUsage adds a file of size bytes to the usage of ownerID, failing
+// with ErrQuotaExceeded if that exceeds its quota. The quota row stays
+// locked until the transaction ends, so concurrent uploads of the same
+// owner cannot overshoot it together.
+func chargeUsage(ctx context.Context, tx *sql.Tx, ownerID string, size int64) error {
+	query := `INSERT INTO quotas (owner_id, used_bytes, used_files) VALUES($1, $2, 1)
+				ON CONFLICT (owner_id) DO UPDATE SET
+					used_bytes = quotas.used_bytes + EXCLUDED.used_bytes,
+					used_files = quotas.used_files + 1
+				RETURNING used_bytes, used_files, max_bytes, max_files`
+
+	usage := domain.Usage{OwnerID: ownerID}
+	err := tx.QueryRowContext(ctx, query, ownerID, size).Scan(&usage.Bytes, &usage.Files, &usage.MaxBytes, &usage.MaxFiles)
+	if err != nil {
+		return err
+	}
+	if !usage.Allows(0, 0) {
+		return fmt.Errorf("%w for %s", domain.ErrQuotaExceeded, ownerID)
+	}
+
+	return nil
+}
+
+// releaseUsage removes a deleted file of size bytes from the usage of
+// ownerID.
+func releaseUsage(ctx context.Context, tx *sql.Tx, ownerID sql.NullString, size int64) error {
+	if !ownerID.Valid {
+		return nil
+	}
+
+	query := `UPDATE quotas SET used_bytes = used_bytes - $2, used_files = used_files - 1 WHERE owner_id = $1`
+	_, err := tx.ExecContext(ctx, query, ownerID.String, size)
+
+	return err
+}
@@ -3,10 +3,33 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// insertColumns are the columns set when a file is saved; fileColumns adds
+// those that are only set later.
+const (
+	insertColumns = `id, filename, version, storage_name, size, stored_size, compression, content_type, path, checksum_sha256, checksum_crc32c, blob_sha256, key_id, wrapped_key, tags, description, labels, owner_id, created_at, updated_at`
+	fileColumns   = insertColumns + `, deleted_at`
 )
 
+// latestVersionCondition matches files that are not superseded by a newer
+// version of the same file, that is of the same filename and owner. Trashed
+// versions supersede nothing.
+const latestVersionCondition = `NOT pending_delete AND deleted_at IS NULL AND NOT EXISTS (
+					SELECT 1 FROM files newer
+					WHERE newer.owner_key = files.owner_key AND newer.filename = files.filename
+						AND newer.version > files.version
+						AND NOT newer.pending_delete AND newer.deleted_at IS NULL)`
+
 type postgresFileRepository struct {
 	db *sql.DB
 }
@@ -17,76 +40,502 @@ func NewPostgresFileRepository(db *sql.DB) FileRepository {
 	}
 }
 
+// Save stores the file as the next version of its filename among the files
+// of its owner and sets file.Version accordingly.
 func (r *postgresFileRepository) Save(ctx context.Context, file *domain.File) error {
-	query := `INSERT INTO files (id, filename, size, path, created_at, updated_at) 
-				VALUES($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.ExecContext(ctx, query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertFile(ctx, tx, file); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveWithBlobRef saves a content-addressed file and takes a reference on
+// its blob. putBlob is called only when no other file references the blob
+// yet; the blob row stays locked until it returns, so concurrent uploads of
+// the same content wait for the first one to finish storing it.
+func (r *postgresFileRepository) SaveWithBlobRef(ctx context.Context, file *domain.File, putBlob func(ctx context.Context) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The path is locked first, so that the lock order is the same as in
+	// Save.
+	if err := lockPath(ctx, tx, file.Filename); err != nil {
+		return err
+	}
+
+	// An existing blob keeps its path, compression and data key, which the
+	// file then inherits. A blob without references may already have lost
+	// its content to DeleteUnreferencedBlobs, so it is stored again like a
+	// new one.
+	query := `INSERT INTO blobs (sha256, path, size, stored_size, compression, key_id, wrapped_key, ref_count)
+				VALUES($1, $2, $3, $4, $5, $6, $7, 1)
+				ON CONFLICT (sha256) DO UPDATE SET
+					ref_count = blobs.ref_count + 1,
+					path = CASE WHEN blobs.ref_count = 0 THEN EXCLUDED.path ELSE blobs.path END,
+					stored_size = CASE WHEN blobs.ref_count = 0 THEN EXCLUDED.stored_size ELSE blobs.stored_size END,
+					compression = CASE WHEN blobs.ref_count = 0 THEN EXCLUDED.compression ELSE blobs.compression END,
+					key_id = CASE WHEN blobs.ref_count = 0 THEN EXCLUDED.key_id ELSE blobs.key_id END,
+					wrapped_key = CASE WHEN blobs.ref_count = 0 THEN EXCLUDED.wrapped_key ELSE blobs.wrapped_key END
+				RETURNING path, stored_size, compression, key_id, wrapped_key, ref_count`
+	var keyID sql.NullString
+	var refCount int
+	err = tx.QueryRowContext(ctx, query,
+		file.BlobSHA256,
+		file.Path,
+		file.Size,
+		file.StoredSize,
+		file.Compression,
+		sql.NullString{String: file.KeyID, Valid: file.KeyID != ""},
+		nullBytes(file.WrappedKey),
+	).Scan(&file.Path, &file.StoredSize, &file.Compression, &keyID, &file.WrappedKey, &refCount)
+	if err != nil {
+		return err
+	}
+
+	file.KeyID = keyID.String
+
+	if err := insertFile(ctx, tx, file); err != nil {
+		return err
+	}
+
+	// The blob is stored last, so a failure after it was written can only
+	// come from the commit.
+	if refCount == 1 {
+		if err := putBlob(ctx); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// insertFile inserts file as the next version of its filename among the
+// files of its owner and creates the folders it is in. It must run in a
+// transaction, which holds the filename lock until it ends.
+func insertFile(ctx context.Context, tx *sql.Tx, file *domain.File) error {
+	if err := lockPath(ctx, tx, file.Filename); err != nil {
+		return err
+	}
+	if exists, err := folderExists(ctx, tx, file.Filename); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("folder %s: %w", file.Filename, domain.ErrAlreadyExists)
+	}
+	if err := ensureFolders(ctx, tx, file.OwnerID, domain.ParentPath(file.Filename)); err != nil {
+		return err
+	}
+
+	labels, err := marshalLabels(file.Labels)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO files (` + insertColumns + `) 
+				VALUES($1, $2, (SELECT COALESCE(MAX(version), 0) + 1 FROM files WHERE owner_key = COALESCE($17, '') AND filename = $2),
+					$3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+				RETURNING version`
+	err = tx.QueryRowContext(ctx, query,
 		file.ID,
 		file.Filename,
+		file.StorageName,
 		file.Size,
+		file.StoredSize,
+		file.Compression,
+		file.ContentType,
 		file.Path,
+		file.ChecksumSHA256,
+		int64(file.ChecksumCRC32C),
+		sql.NullString{String: file.BlobSHA256, Valid: file.BlobSHA256 != ""},
+		sql.NullString{String: file.KeyID, Valid: file.KeyID != ""},
+		nullBytes(file.WrappedKey),
+		pq.Array(nonNilStrings(file.Tags)),
+		file.Description,
+		labels,
+		sql.NullString{String: file.OwnerID, Valid: file.OwnerID != ""},
 		file.CreatedAt,
 		file.UpdatedAt,
-	)
+	).Scan(&file.Version)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// The owner is charged last, so its quota row is locked after the path
+	// and blob locks, in the same order as in the deletes.
+	if file.OwnerID == "" {
+		return nil
+	}
+
+	return chargeUsage(ctx, tx, file.OwnerID, file.Size)
 }
-func (r *postgresFileRepository) GetByFileName(ctx context.Context, fileName string) (*domain.File, error) {
-	query := `SELECT id, filename, size, path, created_at, updated_at FROM files WHERE filename = $1`
 
-	file := &domain.File{}
-	err := r.db.QueryRowContext(ctx, query, fileName).Scan(
-		&file.ID,
-		&file.Filename,
-		&file.Size,
-		&file.Path,
-		&file.CreatedAt,
-		&file.UpdatedAt,
-	)
+// GetByFileName returns the latest version of fileName among the files of
+// ownerID, "" being the files without an owner. The storage name is matched
+// too, for clients that kept the generated names returned before files were
+// addressed by id.
+func (r *postgresFileRepository) GetByFileName(ctx context.Context, ownerID, fileName string) (*domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files
+				WHERE owner_key = $1 AND (filename = $2 OR storage_name = $2) AND NOT pending_delete AND deleted_at IS NULL
+				ORDER BY version DESC
+				LIMIT 1`
+
+	file, err := scanFile(r.db.QueryRowContext(ctx, query, ownerID, fileName))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	return file, nil
 }
-func (r *postgresFileRepository) List(ctx context.Context, page, pageSize int) (*domain.FileList, error) {
-	offset := (page - 1) * pageSize
+func (r *postgresFileRepository) GetByID(ctx context.Context, id string) (*domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $1 AND NOT pending_delete AND deleted_at IS NULL`
 
-	var total int
-	countQuery := `SELECT COUNT(*) FROM files`
-	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
+	file, err := scanFile(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	return file, nil
+}
+
+// List returns the latest version of each filename matching filter.
+func (r *postgresFileRepository) List(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error) {
+	column, ok := sortColumns[opts.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", opts.SortBy)
+	}
+
+	var args queryArgs
+	conditions := append([]string{latestVersionCondition}, filterConditions(filter, &args)...)
+
+	var total int
+	if !opts.SkipTotal {
+		countQuery := `SELECT COUNT(*) FROM files WHERE ` + strings.Join(conditions, " AND ")
+		if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+	}
+
+	offset := ""
+	if opts.After != nil {
+		conditions = append(conditions, cursorCondition(opts.SortBy, opts.Ascending, opts.After, &args))
+	} else {
+		offset = ` OFFSET ` + args.add((opts.Page-1)*opts.PageSize)
+	}
+	direction := "DESC"
+	if opts.Ascending {
+		direction = "ASC"
+	}
+
+	// One extra row is fetched to tell whether another page follows.
 	query := `
-				SELECT id, filename, size, path, created_at, updated_at 
+				SELECT ` + fileColumns + `
 				FROM files
-				ORDER BY created_at DESC
-				LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.QueryContext(ctx, query, pageSize, offset)
+				WHERE ` + strings.Join(conditions, " AND ") + `
+				ORDER BY ` + column + ` ` + direction + `, id ` + direction + `
+				LIMIT ` + args.add(opts.PageSize+1) + offset
+	files, err := r.queryFiles(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	files := make([]domain.File, 0)
+	fileList := &domain.FileList{
+		Files: files,
+		Total: total,
+	}
+	if len(files) > opts.PageSize {
+		fileList.Files = files[:opts.PageSize]
+		fileList.Next = domain.CursorOf(&fileList.Files[opts.PageSize-1])
+	}
+
+	return fileList, nil
+}
+
+// UpdateMetadata changes the client-defined metadata of a file in a single
+// statement, so concurrent updates of different labels do not overwrite
+// each other. It returns the updated file.
+func (r *postgresFileRepository) UpdateMetadata(ctx context.Context, id string, update domain.MetadataUpdate) (*domain.File, error) {
+	setLabels, err := marshalLabels(update.SetLabels)
+	if err != nil {
+		return nil, err
+	}
+	var tags any
+	if update.Tags != nil {
+		tags = pq.Array(update.Tags)
+	}
+
+	query := `UPDATE files SET
+					labels = (labels || $2::jsonb) - $3::text[],
+					tags = COALESCE($4, tags),
+					description = COALESCE($5, description),
+					updated_at = NOW()
+				WHERE id = $1 AND NOT pending_delete AND deleted_at IS NULL
+				RETURNING ` + fileColumns
+	file, err := scanFile(r.db.QueryRowContext(ctx, query,
+		id,
+		setLabels,
+		pq.Array(nonNilStrings(update.RemoveLabels)),
+		tags,
+		update.Description,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// MarkDeleted hides the file from reads before its blob is removed, so a
+// failure halfway through a delete never leaves a row pointing at nothing.
+func (r *postgresFileRepository) MarkDeleted(ctx context.Context, id string) error {
+	query := `UPDATE files SET pending_delete = TRUE, updated_at = NOW() WHERE id = $1 AND NOT pending_delete`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *postgresFileRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var filename string
+	var ownerID sql.NullString
+	var size int64
+	query := `DELETE FROM files WHERE id = $1 RETURNING filename, owner_id, size`
+	err = tx.QueryRowContext(ctx, query, id).Scan(&filename, &ownerID, &size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := deleteOrphanedGrants(ctx, tx, ownerID.String, filename); err != nil {
+		return err
+	}
+	if err := releaseUsage(ctx, tx, ownerID, size); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteWithBlobRef deletes a content-addressed file and releases its blob
+// reference. A blob left without references is kept for
+// DeleteUnreferencedBlobs, so its content is never removed by a transaction
+// that could still roll back.
+func (r *postgresFileRepository) DeleteWithBlobRef(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var filename string
+	var blobSHA256, ownerID sql.NullString
+	var size int64
+	query := `DELETE FROM files WHERE id = $1 RETURNING filename, blob_sha256, owner_id, size`
+	err = tx.QueryRowContext(ctx, query, id).Scan(&filename, &blobSHA256, &ownerID, &size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := deleteOrphanedGrants(ctx, tx, ownerID.String, filename); err != nil {
+		return err
+	}
+
+	if blobSHA256.Valid {
+		query := `UPDATE blobs SET ref_count = ref_count - 1 WHERE sha256 = $1`
+		if _, err := tx.ExecContext(ctx, query, blobSHA256.String); err != nil {
+			return err
+		}
+	}
+
+	// Usage is released after the blob, which Save locks first.
+	if err := releaseUsage(ctx, tx, ownerID, size); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUnreferencedBlobs deletes up to limit blobs without references,
+// calling removeBlob with the path of each first. Blobs whose content
+// could not be removed are kept for the next call, and the first such error
+// is returned along with the number deleted. Uploads of the same content
+// wait for the deletion and then store the blob again.
+func (r *postgresFileRepository) DeleteUnreferencedBlobs(ctx context.Context, limit int, removeBlob func(ctx context.Context, path string) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT sha256, path FROM blobs WHERE ref_count = 0
+				ORDER BY sha256
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED`
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	var checksums, paths []string
 	for rows.Next() {
-		var file domain.File
-		err := rows.Scan(
-			&file.ID,
-			&file.Filename,
-			&file.Size,
-			&file.Path,
-			&file.CreatedAt,
-			&file.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+		var checksum, path string
+		if err := rows.Scan(&checksum, &path); err != nil {
+			rows.Close()
+			return 0, err
 		}
+		checksums = append(checksums, checksum)
+		paths = append(paths, path)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Content removed before a failed commit leaves a blob without
+	// content or references, which the next call deletes again.
+	var removed []string
+	var removeErr error
+	for i, path := range paths {
+		if err := removeBlob(ctx, path); err != nil {
+			if removeErr == nil {
+				removeErr = fmt.Errorf("blob %s: %w", path, err)
+			}
+			continue
+		}
+		removed = append(removed, checksums[i])
+	}
+	if len(removed) == 0 {
+		return 0, removeErr
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blobs WHERE sha256 = ANY($1)`, pq.Array(removed)); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(removed), removeErr
+}
+
+// ListVersions returns all versions of filename among the files of
+// ownerID, newest first.
+func (r *postgresFileRepository) ListVersions(ctx context.Context, ownerID, filename string) ([]domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files
+				WHERE owner_key = $1 AND filename = $2 AND NOT pending_delete AND deleted_at IS NULL
+				ORDER BY version DESC`
+
+	return r.queryFiles(ctx, query, ownerID, filename)
+}
+
+func (r *postgresFileRepository) GetVersionRetention(ctx context.Context, ownerID, filename string) (int, error) {
+	var keepVersions int
+	query := `SELECT keep_versions FROM version_retention WHERE owner_id = $1 AND filename = $2`
+	err := r.db.QueryRowContext(ctx, query, ownerID, filename).Scan(&keepVersions)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrNotFound
+	}
+
+	return keepVersions, err
+}
+
+func (r *postgresFileRepository) SetVersionRetention(ctx context.Context, ownerID, filename string, keepVersions int) error {
+	query := `INSERT INTO version_retention (owner_id, filename, keep_versions) VALUES($1, $2, $3)
+				ON CONFLICT (owner_id, filename) DO UPDATE SET keep_versions = EXCLUDED.keep_versions`
+	_, err := r.db.ExecContext(ctx, query, ownerID, filename, keepVersions)
+
+	return err
+}
+
+// MoveToTrash hides the file from reads without removing it, so it can be
+// restored until it is purged.
+func (r *postgresFileRepository) MoveToTrash(ctx context.Context, id string) error {
+	query := `UPDATE files SET deleted_at = NOW(), updated_at = NOW()
+				WHERE id = $1 AND NOT pending_delete AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
 
-		files = append(files, file)
+func (r *postgresFileRepository) RestoreFromTrash(ctx context.Context, id string) error {
+	query := `UPDATE files SET deleted_at = NULL, updated_at = NOW()
+				WHERE id = $1 AND NOT pending_delete AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *postgresFileRepository) GetTrashedByID(ctx context.Context, id string) (*domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $1 AND NOT pending_delete AND deleted_at IS NOT NULL`
+
+	file, err := scanFile(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ListTrash returns trashed files, most recently deleted first, restricted
+// to those visible to the accessor if it is not nil.
+func (r *postgresFileRepository) ListTrash(ctx context.Context, visibleTo *domain.Accessor, page, pageSize int) (*domain.FileList, error) {
+	var args queryArgs
+	conditions := []string{`NOT pending_delete`, `deleted_at IS NOT NULL`}
+	if visibleTo != nil {
+		conditions = append(conditions, accessCondition(visibleTo, &args))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM files WHERE ` + where
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + fileColumns + ` FROM files
+				WHERE ` + where + `
+				ORDER BY deleted_at DESC
+				LIMIT ` + args.add(pageSize) + ` OFFSET ` + args.add((page-1)*pageSize)
+	files, err := r.queryFiles(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 
 	return &domain.FileList{
@@ -94,3 +543,132 @@ func (r *postgresFileRepository) List(ctx context.Context, page, pageSize int) (
 		Total: total,
 	}, nil
 }
+
+// ListTrashedBefore returns up to limit files moved to the trash before the
+// given time, oldest first.
+func (r *postgresFileRepository) ListTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files
+				WHERE NOT pending_delete AND deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2`
+
+	return r.queryFiles(ctx, query, before, limit)
+}
+
+func (r *postgresFileRepository) queryFiles(ctx context.Context, query string, args ...any) ([]domain.File, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := make([]domain.File, 0)
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, *file)
+	}
+
+	return files, rows.Err()
+}
+
+func (r *postgresFileRepository) ListPendingDeletes(ctx context.Context, limit int) ([]domain.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE pending_delete ORDER BY updated_at LIMIT $1`
+
+	return r.queryFiles(ctx, query, limit)
+}
+
+// scanFile scans a row of fileColumns, followed by any extra columns into
+// the given destinations.
+func scanFile(row rowScanner, extra ...any) (*domain.File, error) {
+	file := &domain.File{}
+	var checksumSHA256 sql.NullString
+	var checksumCRC32C sql.NullInt64
+	var blobSHA256 sql.NullString
+	var keyID sql.NullString
+	var labels []byte
+	var ownerID sql.NullString
+	var deletedAt sql.NullTime
+	dest := []any{
+		&file.ID,
+		&file.Filename,
+		&file.Version,
+		&file.StorageName,
+		&file.Size,
+		&file.StoredSize,
+		&file.Compression,
+		&file.ContentType,
+		&file.Path,
+		&checksumSHA256,
+		&checksumCRC32C,
+		&blobSHA256,
+		&keyID,
+		&file.WrappedKey,
+		pq.Array(&file.Tags),
+		&file.Description,
+		&labels,
+		&ownerID,
+		&file.CreatedAt,
+		&file.UpdatedAt,
+		&deletedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		file.DeletedAt = &deletedAt.Time
+	}
+	if file.Labels, err = unmarshalLabels(labels); err != nil {
+		return nil, err
+	}
+	file.ChecksumSHA256 = checksumSHA256.String
+	file.ChecksumCRC32C = uint32(checksumCRC32C.Int64)
+	file.BlobSHA256 = blobSHA256.String
+	file.KeyID = keyID.String
+	file.OwnerID = ownerID.String
+
+	return file, nil
+}
+
+// marshalLabels stores nil labels as an empty object.
+func marshalLabels(labels map[string]string) ([]byte, error) {
+	if labels == nil {
+		return []byte(`{}`), nil
+	}
+
+	return json.Marshal(labels)
+}
+
+func unmarshalLabels(data []byte) (map[string]string, error) {
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	return labels, nil
+}
+
+// nonNilStrings keeps pq from storing a nil slice as NULL.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
+
+// nullBytes stores empty byte slices as NULL.
+func nullBytes(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+
+	return b
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+
+	filenameHeadlineOptions    = `HighlightAll=true, StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	descriptionHeadlineOptions = `MaxFragments=2, MaxWords=35, MinWords=15, StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+)
+
+// Search returns the latest versions of files matching query, best matches
+// first, restricted to those visible to the accessor if it is not nil.
+// query uses web search syntax: quoted phrases, "or", and "-" to exclude a
+// word.
+func (r *postgresFileRepository) Search(ctx context.Context, query string, visibleTo *domain.Accessor, page, pageSize int) ([]domain.SearchResult, error) {
+	args := queryArgs{query, filenameHeadlineOptions, descriptionHeadlineOptions}
+	conditions := []string{`search_vector @@ search_query`, latestVersionCondition}
+	if visibleTo != nil {
+		conditions = append(conditions, accessCondition(visibleTo, &args))
+	}
+
+	// The filename is highlighted with punctuation replaced the same way as
+	// when it was indexed; highlightFilename maps the result back.
+	sqlQuery := `
+				SELECT ` + fileColumns + `,
+					ts_rank_cd(search_vector, search_query) AS rank,
+					ts_headline('english', regexp_replace(filename, '[^[:alnum:]]+', ' ', 'g'), search_query, $2),
+					ts_headline('english', description, search_query, $3)
+				FROM files, websearch_to_tsquery('english', $1) AS search_query
+				WHERE ` + strings.Join(conditions, " AND ") + `
+				ORDER BY rank DESC, created_at DESC, id DESC
+				LIMIT ` + args.add(pageSize) + ` OFFSET ` + args.add((page-1)*pageSize)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]domain.SearchResult, 0)
+	for rows.Next() {
+		var result domain.SearchResult
+		var filenameHeadline string
+		file, err := scanFile(rows, &result.Rank, &filenameHeadline, &result.DescriptionHighlight)
+		if err != nil {
+			return nil, err
+		}
+		result.File = *file
+		result.FilenameHighlight = highlightFilename(file.Filename, filenameHeadline)
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// highlightFilename copies the highlights in headline, which holds the
+// words of filename separated by single spaces, onto filename itself. It
+// returns filename unchanged if the words do not line up.
+func highlightFilename(filename, headline string) string {
+	words := strings.Fields(headline)
+	var highlighted strings.Builder
+	rest := filename
+	for len(rest) > 0 {
+		start := strings.IndexFunc(rest, isWordRune)
+		if start < 0 {
+			highlighted.WriteString(rest)
+			break
+		}
+		highlighted.WriteString(rest[:start])
+		rest = rest[start:]
+
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		if len(words) == 0 || stripHighlights(words[0]) != rest[:end] {
+			return filename
+		}
+		highlighted.WriteString(words[0])
+		words = words[1:]
+		rest = rest[end:]
+	}
+	if len(words) > 0 {
+		return filename
+	}
+
+	return highlighted.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func stripHighlights(s string) string {
+	return strings.NewReplacer(highlightStart, "", highlightStop, "").Replace(s)
+}
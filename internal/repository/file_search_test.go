@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HighlightFilename(t *testing.T) {
+	assert.Equal(t,
+		"<mark>quarterly</mark>_<mark>invoice</mark>-2024.pdf",
+		highlightFilename("quarterly_invoice-2024.pdf", "<mark>quarterly</mark> <mark>invoice</mark> 2024 pdf"))
+	assert.Equal(t,
+		"../<mark>Invoices</mark> (final).pdf",
+		highlightFilename("../Invoices (final).pdf", "<mark>Invoices</mark> final pdf"))
+}
+
+func Test_HighlightFilename_KeepsFilenameIfWordsDiffer(t *testing.T) {
+	assert.Equal(t, "invoice.pdf", highlightFilename("invoice.pdf", "<mark>invoice</mark>"))
+	assert.Equal(t, "invoice.pdf", highlightFilename("invoice.pdf", "<mark>invoice</mark> pdf extra"))
+}
@@ -2,12 +2,52 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/grpc-file-storage-go/internal/domain"
 )
 
 type FileRepository interface {
 	Save(ctx context.Context, file *domain.File) error
-	GetByFileName(ctx context.Context, fileName string) (*domain.File, error)
-	List(ctx context.Context, page, pageSize int) (*domain.FileList, error)
+	SaveWithBlobRef(ctx context.Context, file *domain.File, putBlob func(ctx context.Context) error) error
+	GetByFileName(ctx context.Context, ownerID, fileName string) (*domain.File, error)
+	GetByID(ctx context.Context, id string) (*domain.File, error)
+	List(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error)
+	Search(ctx context.Context, query string, visibleTo *domain.Accessor, page, pageSize int) ([]domain.SearchResult, error)
+	UpdateMetadata(ctx context.Context, id string, update domain.MetadataUpdate) (*domain.File, error)
+	MarkDeleted(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	DeleteWithBlobRef(ctx context.Context, id string) error
+	DeleteUnreferencedBlobs(ctx context.Context, limit int, removeBlob func(ctx context.Context, path string) error) (int, error)
+	ListPendingDeletes(ctx context.Context, limit int) ([]domain.File, error)
+	ListVersions(ctx context.Context, ownerID, filename string) ([]domain.File, error)
+	GetVersionRetention(ctx context.Context, ownerID, filename string) (int, error)
+	SetVersionRetention(ctx context.Context, ownerID, filename string, keepVersions int) error
+	MoveToTrash(ctx context.Context, id string) error
+	RestoreFromTrash(ctx context.Context, id string) error
+	GetTrashedByID(ctx context.Context, id string) (*domain.File, error)
+	ListTrash(ctx context.Context, visibleTo *domain.Accessor, page, pageSize int) (*domain.FileList, error)
+	ListTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.File, error)
+	CreateFolder(ctx context.Context, ownerID, path string) (*domain.Folder, error)
+	ListFolder(ctx context.Context, path string, visibleTo *domain.Accessor, after *domain.FolderCursor, limit int) (*domain.FolderListing, error)
+	Rename(ctx context.Context, ownerID, filename, newFilename string) error
+	GetPermission(ctx context.Context, ownerID, filename string, accessor domain.Accessor) (domain.Permission, error)
+	PutGrant(ctx context.Context, grant *domain.Grant) error
+	DeleteGrant(ctx context.Context, ownerID, filename string, grantee domain.Grantee) error
+	ListGrants(ctx context.Context, ownerID, filename string) ([]domain.Grant, error)
+	GetUsage(ctx context.Context, ownerID string) (*domain.Usage, error)
+	SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error)
+}
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *domain.UploadSession) error
+	GetByID(ctx context.Context, id string) (*domain.UploadSession, error)
+	Update(ctx context.Context, session *domain.UploadSession) error
+	Delete(ctx context.Context, id string) error
+	ListExpired(ctx context.Context, before time.Time) ([]domain.UploadSession, error)
+}
+
+type DataKeyRepository interface {
+	ListStale(ctx context.Context, activeKeyID string, limit int) ([]domain.WrappedDataKey, error)
+	Rewrap(ctx context.Context, old domain.WrappedDataKey, keyID string, wrappedKey []byte) error
 }
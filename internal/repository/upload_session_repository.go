@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+const uploadSessionColumns = `id, filename, content_type, tags, description, labels, declared_size,
+				expected_sha256, expected_crc32c, owner_id, bytes_received, created_at, updated_at, expires_at`
+
+type postgresUploadSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUploadSessionRepository(db *sql.DB) UploadSessionRepository {
+	return &postgresUploadSessionRepository{
+		db: db,
+	}
+}
+
+func (r *postgresUploadSessionRepository) Create(ctx context.Context, session *domain.UploadSession) error {
+	query := `INSERT INTO upload_sessions (` + uploadSessionColumns + `)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	labels, err := marshalLabels(session.Labels)
+	if err != nil {
+		return err
+	}
+	var expectedCRC32C *int64
+	if session.ExpectedCRC32C != nil {
+		crc := int64(*session.ExpectedCRC32C)
+		expectedCRC32C = &crc
+	}
+	_, err = r.db.ExecContext(ctx, query,
+		session.ID,
+		session.Filename,
+		sql.NullString{String: session.ContentType, Valid: session.ContentType != ""},
+		pq.Array(nonNilStrings(session.Tags)),
+		session.Description,
+		labels,
+		session.DeclaredSize,
+		sql.NullString{String: session.ExpectedSHA256, Valid: session.ExpectedSHA256 != ""},
+		expectedCRC32C,
+		sql.NullString{String: session.OwnerID, Valid: session.OwnerID != ""},
+		session.BytesReceived,
+		session.CreatedAt,
+		session.UpdatedAt,
+		session.ExpiresAt,
+	)
+
+	return err
+}
+
+func (r *postgresUploadSessionRepository) GetByID(ctx context.Context, id string) (*domain.UploadSession, error) {
+	query := `SELECT ` + uploadSessionColumns + ` FROM upload_sessions WHERE id = $1`
+
+	session, err := scanUploadSession(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r *postgresUploadSessionRepository) Update(ctx context.Context, session *domain.UploadSession) error {
+	query := `UPDATE upload_sessions SET bytes_received = $2, updated_at = $3, expires_at = $4 WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.BytesReceived,
+		session.UpdatedAt,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *postgresUploadSessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id)
+
+	return err
+}
+
+func (r *postgresUploadSessionRepository) ListExpired(ctx context.Context, before time.Time) ([]domain.UploadSession, error) {
+	query := `SELECT ` + uploadSessionColumns + `
+				FROM upload_sessions
+				WHERE expires_at < $1
+				ORDER BY expires_at`
+	rows, err := r.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.UploadSession, 0)
+	for rows.Next() {
+		session, err := scanUploadSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUploadSession(row rowScanner) (*domain.UploadSession, error) {
+	session := &domain.UploadSession{}
+	var contentType sql.NullString
+	var labels []byte
+	var declaredSize sql.NullInt64
+	var expectedSHA256 sql.NullString
+	var expectedCRC32C sql.NullInt64
+	var ownerID sql.NullString
+	err := row.Scan(
+		&session.ID,
+		&session.Filename,
+		&contentType,
+		pq.Array(&session.Tags),
+		&session.Description,
+		&labels,
+		&declaredSize,
+		&expectedSHA256,
+		&expectedCRC32C,
+		&ownerID,
+		&session.BytesReceived,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.ContentType = contentType.String
+	if session.Labels, err = unmarshalLabels(labels); err != nil {
+		return nil, err
+	}
+	if declaredSize.Valid {
+		session.DeclaredSize = &declaredSize.Int64
+	}
+	session.ExpectedSHA256 = expectedSHA256.String
+	if expectedCRC32C.Valid {
+		crc := uint32(expectedCRC32C.Int64)
+		session.ExpectedCRC32C = &crc
+	}
+	session.OwnerID = ownerID.String
+
+	return session, nil
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
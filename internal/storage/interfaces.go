@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	Key        string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// BlobStore keeps file contents addressed by key. Keys are slash-separated
+// relative paths. Missing keys are reported as domain.ErrNotFound, except by
+// Delete, which treats them as already deleted.
+type BlobStore interface {
+	// Put stores data under key and returns the number of bytes written.
+	// A failed Put leaves nothing behind under key.
+	Put(ctx context.Context, key string, data io.Reader) (int64, error)
+	// Get reads length bytes starting at offset. A negative length reads
+	// to the end of the blob.
+	Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
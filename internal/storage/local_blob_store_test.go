@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LocalBlobStore_PutGetRange(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+	ctx := context.Background()
+
+	size, err := store.Put(ctx, "ab/blob", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+
+	reader, err := store.Get(ctx, "ab/blob", 6, 3)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "wor", string(data))
+
+	reader, err = store.Get(ctx, "ab/blob", 6, -1)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "world", string(data))
+
+	info, err := store.Stat(ctx, "ab/blob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+}
+
+func Test_LocalBlobStore_FailedPutLeavesNothing(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalBlobStore(root)
+	ctx := context.Background()
+
+	reader := io.MultiReader(strings.NewReader("partial"), &failingReader{})
+	_, err := store.Put(ctx, "blob", reader)
+	require.Error(t, err)
+
+	_, err = store.Stat(ctx, "blob")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_LocalBlobStore_DeleteAndList(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalBlobStore(root)
+	ctx := context.Background()
+
+	for _, key := range []string{"a/1", "a/2", "b/1"} {
+		_, err := store.Put(ctx, key, strings.NewReader(key))
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".staging"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".staging", "upload"), []byte("x"), 0644))
+
+	blobs, err := store.List(ctx, "a/")
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2)
+
+	require.NoError(t, store.Delete(ctx, "a/1"))
+	require.NoError(t, store.Delete(ctx, "a/1"), "deleting a missing blob is not an error")
+
+	blobs, err = store.List(ctx, "")
+	require.NoError(t, err)
+	keys := make([]string, len(blobs))
+	for i, blob := range blobs {
+		keys[i] = blob.Key
+	}
+	assert.ElementsMatch(t, []string{"a/2", "b/1"}, keys)
+
+	_, err = store.Get(ctx, "a/1", 0, -1)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func Test_LocalBlobStore_RejectsEscapingKeys(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+
+	_, err := store.Put(context.Background(), "../outside", strings.NewReader("x"))
+	assert.Error(t, err)
+	_, err = store.Put(context.Background(), "/etc/passwd", strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+type failingReader struct{}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
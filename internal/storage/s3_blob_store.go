@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Options configures the bucket an S3 blob store keeps its objects in.
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	// CreateBucket creates the bucket if it does not exist yet.
+	CreateBucket bool
+	// PartSize is the size of each part of a multipart upload.
+	PartSize int64
+}
+
+type s3BlobStore struct {
+	client   *minio.Client
+	bucket   string
+	partSize uint64
+}
+
+// NewS3BlobStore stores blobs as objects in an S3-compatible bucket, so
+// every server replica sees the same files.
+func NewS3BlobStore(ctx context.Context, opts S3Options) (BlobStore, error) {
+	return newS3BlobStore(ctx, opts, nil)
+}
+
+func newS3BlobStore(ctx context.Context, opts S3Options, transport http.RoundTripper) (BlobStore, error) {
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, ""),
+		Secure:    opts.UseSSL,
+		Region:    opts.Region,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket %s: %w", opts.Bucket, err)
+	}
+	if !exists {
+		if !opts.CreateBucket {
+			return nil, fmt.Errorf("bucket %s does not exist", opts.Bucket)
+		}
+		if err := client.MakeBucket(ctx, opts.Bucket, minio.MakeBucketOptions{Region: opts.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket %s: %w", opts.Bucket, err)
+		}
+	}
+
+	return &s3BlobStore{
+		client:   client,
+		bucket:   opts.Bucket,
+		partSize: uint64(opts.PartSize),
+	}, nil
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, key string, data io.Reader) (int64, error) {
+	// The size is unknown up front, so the upload always goes through
+	// multipart and only one part is buffered at a time. A failed upload is
+	// aborted and never becomes visible under key.
+	info, err := s.client.PutObject(ctx, s.bucket, key, data, -1, minio.PutObjectOptions{
+		PartSize: s.partSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size, nil
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		// S3 cannot express an empty range.
+		if _, err := s.Stat(ctx, key); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	opts := minio.GetObjectOptions{}
+	switch {
+	case length > 0:
+		if err := opts.SetRange(offset, offset+length-1); err != nil {
+			return nil, err
+		}
+	case offset > 0:
+		if err := opts.SetRange(offset, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	// The core API issues a single GET with exactly this range and reports
+	// a missing key right away instead of on the first Read.
+	core := minio.Core{Client: s.client}
+	object, _, _, err := core.GetObject(ctx, s.bucket, key, opts)
+	if err != nil {
+		return nil, s.mapError(key, err)
+	}
+
+	return object, nil
+}
+
+func (s *s3BlobStore) Delete(ctx context.Context, key string) error {
+	// S3 reports success for missing keys, matching the BlobStore contract.
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *s3BlobStore) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s.mapError(key, err)
+	}
+
+	return &BlobInfo{
+		Key:        key,
+		Size:       info.Size,
+		ModifiedAt: info.LastModified,
+	}, nil
+}
+
+func (s *s3BlobStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	blobs := make([]BlobInfo, 0)
+
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		blobs = append(blobs, BlobInfo{
+			Key:        object.Key,
+			Size:       object.Size,
+			ModifiedAt: object.LastModified,
+		})
+	}
+
+	return blobs, nil
+}
+
+func (s *s3BlobStore) mapError(key string, err error) error {
+	response := minio.ToErrorResponse(err)
+	if response.Code == "NoSuchKey" || response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("blob %s: %w", key, domain.ErrNotFound)
+	}
+
+	return err
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 implements the subset of the S3 API used by s3BlobStore, with
+// path-style addressing and no signature checks.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+	uploads map[string]map[int][]byte
+	nextID  int
+
+	completedParts int
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		buckets: make(map[string]map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	if key == "" {
+		f.serveBucket(w, r, bucket)
+		return
+	}
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		uploadID := strconv.Itoa(f.nextID)
+		f.uploads[uploadID] = make(map[int][]byte)
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: uploadID})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(r.Body)
+		parts[number] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var object []byte
+		for _, number := range numbers {
+			object = append(object, parts[number]...)
+		}
+		objects[key] = object
+		f.completedParts += len(parts)
+		delete(f.uploads, query.Get("uploadId"))
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: `"object"`})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		objects[key] = data
+		w.Header().Set("ETag", `"object"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"object"`)
+		http.ServeContent(w, r, key, time.Unix(1700000000, 0), bytes.NewReader(data))
+	case r.Method == http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	objects, ok := f.buckets[bucket]
+
+	switch {
+	case r.Method == http.MethodPut:
+		f.buckets[bucket] = make(map[string][]byte)
+	case !ok:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case r.Method == http.MethodHead:
+	case r.URL.Query().Has("location"):
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+		}{})
+	case r.URL.Query().Get("list-type") == "2":
+		prefix := r.URL.Query().Get("prefix")
+		type content struct {
+			Key          string
+			LastModified string
+			ETag         string
+			Size         int64
+		}
+		result := struct {
+			XMLName     xml.Name `xml:"ListBucketResult"`
+			Name        string
+			Prefix      string
+			KeyCount    int
+			MaxKeys     int
+			IsTruncated bool
+			Contents    []content
+		}{Name: bucket, Prefix: prefix, MaxKeys: 1000}
+		keys := make([]string, 0, len(objects))
+		for key := range objects {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result.Contents = append(result.Contents, content{
+				Key:          key,
+				LastModified: time.Unix(1700000000, 0).UTC().Format(time.RFC3339),
+				ETag:         `"object"`,
+				Size:         int64(len(objects[key])),
+			})
+		}
+		result.KeyCount = len(result.Contents)
+		writeXML(w, result)
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: code})
+}
+
+func newTestS3BlobStore(t *testing.T, fake *fakeS3) BlobStore {
+	t.Helper()
+
+	server := httptest.NewTLSServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := newS3BlobStore(context.Background(), S3Options{
+		Endpoint:        strings.TrimPrefix(server.URL, "https://"),
+		Region:          "us-east-1",
+		Bucket:          "files",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		UseSSL:          true,
+		CreateBucket:    true,
+		PartSize:        5 << 20,
+	}, server.Client().Transport)
+	require.NoError(t, err)
+
+	return store
+}
+
+func Test_S3BlobStore_PutGetRange(t *testing.T) {
+	store := newTestS3BlobStore(t, newFakeS3())
+	ctx := context.Background()
+
+	size, err := store.Put(ctx, "ab/blob", strings.NewReader("hello world"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+
+	reader, err := store.Get(ctx, "ab/blob", 6, 3)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "wor", string(data))
+
+	reader, err = store.Get(ctx, "ab/blob", 6, -1)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "world", string(data))
+
+	reader, err = store.Get(ctx, "ab/blob", 11, 0)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Empty(t, data)
+
+	info, err := store.Stat(ctx, "ab/blob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+}
+
+func Test_S3BlobStore_MultipartPut(t *testing.T) {
+	fake := newFakeS3()
+	store := newTestS3BlobStore(t, fake)
+	ctx := context.Background()
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), (11<<20)/16)
+	size, err := store.Put(ctx, "big", bytes.NewReader(payload))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(payload)), size)
+	assert.Equal(t, 3, fake.completedParts)
+
+	reader, err := store.Get(ctx, "big", 0, -1)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, payload, data)
+}
+
+func Test_S3BlobStore_FailedPutLeavesNothing(t *testing.T) {
+	fake := newFakeS3()
+	store := newTestS3BlobStore(t, fake)
+	ctx := context.Background()
+
+	reader := io.MultiReader(strings.NewReader("partial"), &failingReader{})
+	_, err := store.Put(ctx, "blob", reader)
+	require.Error(t, err)
+
+	_, err = store.Stat(ctx, "blob")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	assert.Empty(t, fake.uploads)
+}
+
+func Test_S3BlobStore_DeleteAndList(t *testing.T) {
+	store := newTestS3BlobStore(t, newFakeS3())
+	ctx := context.Background()
+
+	for _, key := range []string{"a/1", "a/2", "b/1"} {
+		_, err := store.Put(ctx, key, strings.NewReader(key))
+		require.NoError(t, err)
+	}
+
+	blobs, err := store.List(ctx, "a/")
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	assert.Equal(t, "a/1", blobs[0].Key)
+	assert.Equal(t, int64(3), blobs[0].Size)
+
+	require.NoError(t, store.Delete(ctx, "a/1"))
+	require.NoError(t, store.Delete(ctx, "a/1"))
+
+	_, err = store.Get(ctx, "a/1", 0, -1)
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+
+	blobs, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, blobs, 2)
+}
@@ -0,0 +1,97 @@
+package tlsconfig
+
+import (
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reloadInterval is how often the files are checked for changes.
+const reloadInterval = 10 * time.Second
+
+// reloader holds a value loaded from files, and loads it again once they
+// change. If that fails, say because a certificate was replaced but its key
+// not yet, the previous value is kept until the next check.
+type reloader[T any] struct {
+	files    []string
+	load     func() (T, error)
+	interval time.Duration
+
+	mu        sync.Mutex
+	value     T
+	stamps    []fileStamp
+	checkedAt time.Time
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func newReloader[T any](load func() (T, error), files ...string) (*reloader[T], error) {
+	r := &reloader[T]{
+		files:    files,
+		load:     load,
+		interval: reloadInterval,
+	}
+
+	stamps, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if r.value, err = load(); err != nil {
+		return nil, err
+	}
+	r.stamps = stamps
+	r.checkedAt = time.Now()
+
+	return r, nil
+}
+
+func (r *reloader[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < r.interval {
+		return r.value
+	}
+	r.checkedAt = time.Now()
+
+	// The files are stat'ed before loading them, so changes made while
+	// loading are picked up by the next check.
+	stamps, err := r.stat()
+	if err != nil {
+		log.Printf("failed to check %s for changes: %v", strings.Join(r.files, ", "), err)
+		return r.value
+	}
+	if slices.Equal(stamps, r.stamps) {
+		return r.value
+	}
+
+	value, err := r.load()
+	if err != nil {
+		log.Printf("failed to reload %s: %v", strings.Join(r.files, ", "), err)
+		return r.value
+	}
+	r.value = value
+	r.stamps = stamps
+	log.Printf("reloaded %s", strings.Join(r.files, ", "))
+
+	return r.value
+}
+
+func (r *reloader[T]) stat() ([]fileStamp, error) {
+	stamps := make([]fileStamp, 0, len(r.files))
+	for _, file := range r.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{modTime: info.ModTime(), size: info.Size()})
+	}
+
+	return stamps, nil
+}
@@ -0,0 +1,104 @@
+// Package tlsconfig builds TLS configs from PEM files. Server certificates
+// and client CAs are reloaded when their files change, so they can be
+// rotated without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// NewServerConfig serves the certificate in certFile and keyFile. If
+// clientCAFile is set, clients must present a certificate signed by one of
+// the CAs in it.
+func NewServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := newReloader(func() (*tls.Certificate, error) {
+		return loadKeyPair(certFile, keyFile)
+	}, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert.get(), nil
+		},
+	}
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	clientCAs, err := newReloader(func() (*x509.CertPool, error) {
+		return loadCertPool(clientCAFile)
+	}, clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+
+	// ClientCAs cannot be swapped on a config in use, so each handshake
+	// gets a copy with the current ones.
+	serverConfig := config.Clone()
+	serverConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshakeConfig := config.Clone()
+		handshakeConfig.ClientCAs = clientCAs.get()
+
+		return handshakeConfig, nil
+	}
+
+	return serverConfig, nil
+}
+
+// NewClientConfig verifies servers against the CAs in caFile, or the
+// system roots if it is empty, and presents the certificate in certFile and
+// keyFile if they are set.
+func NewClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		rootCAs, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = rootCAs
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := loadKeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{*cert}
+	}
+
+	return config, nil
+}
+
+func loadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair %s: %w", certFile, err)
+	}
+
+	return &cert, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+
+	return pool, nil
+}
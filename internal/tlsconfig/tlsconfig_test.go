@@ -0,0 +1,238 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert issues a certificate for commonName, signed by parent or
+// self-signed as a CA if parent is nil.
+func newTestCert(t *testing.T, commonName string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, OrganizationalUnit: []string{"engineering"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	issuer, issuerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+// write stores the certificate and its key as PEM files in dir, named
+// after name.
+func (c *testCert) write(t *testing.T, dir, name string) (certFile, keyFile string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certFile, keyFile
+}
+
+// handshake connects to a server with serverConfig using clientConfig, and
+// returns the certificates each side presented.
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) (clientCerts, serverCerts []*x509.Certificate, err error) {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	accepted := make(chan []*x509.Certificate, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			accepted <- nil
+			return
+		}
+		defer conn.Close()
+
+		tlsConn := conn.(*tls.Conn)
+		if tlsConn.Handshake() != nil {
+			accepted <- nil
+			return
+		}
+		accepted <- tlsConn.ConnectionState().PeerCertificates
+		conn.Write([]byte{1})
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), clientConfig)
+	if err == nil {
+		// TLS 1.3 clients finish before the server verified them, so a
+		// rejected certificate only shows when reading.
+		_, err = conn.Read(make([]byte, 1))
+		serverCerts = conn.ConnectionState().PeerCertificates
+		conn.Close()
+	}
+
+	return <-accepted, serverCerts, err
+}
+
+func Test_ServerConfig_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCertFile, serverKeyFile := newTestCert(t, "localhost", ca).write(t, dir, "server")
+	clientCertFile, clientKeyFile := newTestCert(t, "alice", ca).write(t, dir, "client")
+
+	serverConfig, err := NewServerConfig(serverCertFile, serverKeyFile, caFile)
+	require.NoError(t, err)
+
+	clientConfig, err := NewClientConfig(caFile, clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+	clientConfig.ServerName = "localhost"
+
+	clientCerts, serverCerts, err := handshake(t, serverConfig, clientConfig)
+	require.NoError(t, err)
+	require.Len(t, clientCerts, 1)
+	assert.Equal(t, "alice", clientCerts[0].Subject.CommonName)
+	assert.Equal(t, "localhost", serverCerts[0].Subject.CommonName)
+
+	anonymousConfig, err := NewClientConfig(caFile, "", "")
+	require.NoError(t, err)
+	anonymousConfig.ServerName = "localhost"
+
+	_, _, err = handshake(t, serverConfig, anonymousConfig)
+	assert.Error(t, err)
+}
+
+func Test_ServerConfig_UntrustedClient(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCertFile, serverKeyFile := newTestCert(t, "localhost", ca).write(t, dir, "server")
+	otherCA := newTestCert(t, "other-ca", nil)
+	clientCertFile, clientKeyFile := newTestCert(t, "mallory", otherCA).write(t, dir, "client")
+
+	serverConfig, err := NewServerConfig(serverCertFile, serverKeyFile, caFile)
+	require.NoError(t, err)
+	clientConfig, err := NewClientConfig(caFile, clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+	clientConfig.ServerName = "localhost"
+
+	_, _, err = handshake(t, serverConfig, clientConfig)
+
+	assert.Error(t, err)
+}
+
+func Test_Reloader_PicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	certFile, keyFile := newTestCert(t, "localhost", ca).write(t, dir, "server")
+
+	cert, err := newReloader(func() (*tls.Certificate, error) {
+		return loadKeyPair(certFile, keyFile)
+	}, certFile, keyFile)
+	require.NoError(t, err)
+	cert.interval = 0
+	first := cert.get().Leaf
+
+	rotated := newTestCert(t, "localhost", ca)
+	rotated.write(t, dir, "server")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	assert.NotEqual(t, first.SerialNumber, cert.get().Leaf.SerialNumber)
+	assert.Equal(t, rotated.cert.SerialNumber, cert.get().Leaf.SerialNumber)
+}
+
+func Test_Reloader_KeepsCertificateUntilRotationCompletes(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	original := newTestCert(t, "localhost", ca)
+	certFile, keyFile := original.write(t, dir, "server")
+
+	cert, err := newReloader(func() (*tls.Certificate, error) {
+		return loadKeyPair(certFile, keyFile)
+	}, certFile, keyFile)
+	require.NoError(t, err)
+	cert.interval = 0
+
+	// Only the certificate is replaced so far, which does not match the key.
+	rotated := newTestCert(t, "localhost", ca)
+	rotatedDir := t.TempDir()
+	rotatedCertFile, rotatedKeyFile := rotated.write(t, rotatedDir, "server")
+	data, err := os.ReadFile(rotatedCertFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, data, 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	assert.Equal(t, original.cert.SerialNumber, cert.get().Leaf.SerialNumber)
+
+	data, err = os.ReadFile(rotatedKeyFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, data, 0600))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+
+	assert.Equal(t, rotated.cert.SerialNumber, cert.get().Leaf.SerialNumber)
+}
+
+func Test_Reloader_ChecksAtInterval(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	original := newTestCert(t, "localhost", ca)
+	certFile, keyFile := original.write(t, dir, "server")
+
+	cert, err := newReloader(func() (*tls.Certificate, error) {
+		return loadKeyPair(certFile, keyFile)
+	}, certFile, keyFile)
+	require.NoError(t, err)
+
+	newTestCert(t, "localhost", ca).write(t, dir, "server")
+
+	assert.Equal(t, original.cert.SerialNumber, cert.get().Leaf.SerialNumber)
+}
+
+func Test_NewServerConfig_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewServerConfig(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), "")
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grpc-file-storage-go/internal/auth"
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+// accessorOf returns the authenticated caller, or nil if the call is not
+// authenticated. Such calls are not restricted: they are made with
+// authentication disabled, or from within the server, like the janitor.
+func accessorOf(ctx context.Context) *domain.Accessor {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return &domain.Accessor{
+		ID:     principal.ID,
+		Groups: principal.Groups,
+	}
+}
+
+// ownerIDOf returns the owner whose files the caller names by filename:
+// the caller itself, or "" for the files without an owner if the call is
+// not authenticated. Filenames of other owners are never looked up, so
+// callers cannot tell whether they are taken.
+func ownerIDOf(ctx context.Context) string {
+	if accessor := accessorOf(ctx); accessor != nil {
+		return accessor.ID
+	}
+
+	return ""
+}
+
+// authorize checks that the caller holds permission on file. Callers that
+// cannot even read it get ErrNotFound, so they cannot tell whether it
+// exists.
+func (uc *fileUseCase) authorize(ctx context.Context, file *domain.File, permission domain.Permission) error {
+	accessor := accessorOf(ctx)
+	if accessor == nil {
+		return nil
+	}
+
+	granted, err := uc.permissionOf(ctx, accessor, file)
+	if err != nil {
+		return err
+	}
+	if !granted.Includes(domain.PermissionRead) {
+		return fmt.Errorf("file %s: %w", file.ID, domain.ErrNotFound)
+	}
+	if !granted.Includes(permission) {
+		return fmt.Errorf("%w: %s access to file %s", domain.ErrPermissionDenied, permission, file.ID)
+	}
+
+	return nil
+}
+
+// authorizeID is authorize for the file with the given id. It skips
+// looking the file up for callers that are not restricted.
+func (uc *fileUseCase) authorizeID(ctx context.Context, fileID string, permission domain.Permission) error {
+	if accessorOf(ctx) == nil {
+		return nil
+	}
+
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	return uc.authorize(ctx, file, permission)
+}
+
+// permissionOf returns the permission the accessor holds on file. Files
+// without an owner were uploaded while authentication was disabled; anyone
+// can read and write them, but nobody can share them.
+func (uc *fileUseCase) permissionOf(ctx context.Context, accessor *domain.Accessor, file *domain.File) (domain.Permission, error) {
+	switch file.OwnerID {
+	case "":
+		return domain.PermissionWrite, nil
+	case accessor.ID:
+		return domain.PermissionOwner, nil
+	default:
+		return uc.repo.GetPermission(ctx, file.OwnerID, file.Filename, *accessor)
+	}
+}
+
+// ShareFile grants a user or group read or write access to all versions
+// of a file. Only the owner can share a file.
+func (uc *fileUseCase) ShareFile(ctx context.Context, fileID string, grantee domain.Grantee, permission domain.Permission) (*domain.Grant, error) {
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionOwner); err != nil {
+		return nil, err
+	}
+
+	grant := &domain.Grant{
+		OwnerID:    file.OwnerID,
+		Filename:   file.Filename,
+		Grantee:    grantee,
+		Permission: permission,
+	}
+	if err := uc.repo.PutGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	return grant, nil
+}
+
+func (uc *fileUseCase) UnshareFile(ctx context.Context, fileID string, grantee domain.Grantee) error {
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionOwner); err != nil {
+		return err
+	}
+
+	return uc.repo.DeleteGrant(ctx, file.OwnerID, file.Filename, grantee)
+}
+
+func (uc *fileUseCase) ListFileGrants(ctx context.Context, fileID string) ([]domain.Grant, error) {
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionOwner); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListGrants(ctx, file.OwnerID, file.Filename)
+}
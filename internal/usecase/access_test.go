@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/auth"
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessTestUseCase(t *testing.T) (*MockFileRepository, FileUseCase) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	aliceFile := &domain.File{ID: "file-uuid", Filename: "reports/q3.pdf", OwnerID: "alice"}
+	mockRepo.On("GetByID", mock.Anything, "file-uuid").Return(aliceFile, nil)
+
+	return mockRepo, uc
+}
+
+func asPrincipal(id string, groups ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{ID: id, Groups: groups})
+}
+
+func Test_Access_StrangerGetsNotFound(t *testing.T) {
+	mockRepo, uc := newAccessTestUseCase(t)
+	mockRepo.On("GetPermission", mock.Anything, "alice", "reports/q3.pdf", domain.Accessor{ID: "mallory"}).Return(domain.PermissionNone, nil)
+
+	_, _, err := uc.DownLoadFile(asPrincipal("mallory"), "file-uuid", domain.ByteRange{Length: -1})
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func Test_Access_ReaderCannotDelete(t *testing.T) {
+	mockRepo, uc := newAccessTestUseCase(t)
+	mockRepo.On("GetPermission", mock.Anything, "alice", "reports/q3.pdf", domain.Accessor{ID: "bob"}).Return(domain.PermissionRead, nil)
+
+	file, err := uc.GetFileByID(asPrincipal("bob"), "file-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", file.OwnerID)
+
+	err = uc.DeleteFile(asPrincipal("bob"), "file-uuid")
+
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	mockRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything)
+}
+
+func Test_Access_GroupWriterCanUpdate(t *testing.T) {
+	mockRepo, uc := newAccessTestUseCase(t)
+	accessor := domain.Accessor{ID: "carol", Groups: []string{"finance"}}
+	mockRepo.On("GetPermission", mock.Anything, "alice", "reports/q3.pdf", accessor).Return(domain.PermissionWrite, nil)
+	mockRepo.On("UpdateMetadata", mock.Anything, "file-uuid", mock.Anything).Return(&domain.File{ID: "file-uuid"}, nil)
+
+	_, err := uc.UpdateFileMetadata(asPrincipal("carol", "finance"), "file-uuid", domain.MetadataUpdate{})
+
+	assert.NoError(t, err)
+}
+
+func Test_Access_OnlyOwnerShares(t *testing.T) {
+	mockRepo, uc := newAccessTestUseCase(t)
+	grantee := domain.Grantee{Type: domain.GranteeGroup, ID: "finance"}
+	mockRepo.On("GetPermission", mock.Anything, "alice", "reports/q3.pdf", domain.Accessor{ID: "bob"}).Return(domain.PermissionWrite, nil)
+	mockRepo.On("PutGrant", mock.Anything, mock.AnythingOfType("*domain.Grant")).Return(nil)
+
+	_, err := uc.ShareFile(asPrincipal("bob"), "file-uuid", grantee, domain.PermissionRead)
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+	grant, err := uc.ShareFile(asPrincipal("alice"), "file-uuid", grantee, domain.PermissionRead)
+	require.NoError(t, err)
+	assert.Equal(t, "reports/q3.pdf", grant.Filename)
+	mockRepo.AssertNumberOfCalls(t, "PutGrant", 1)
+}
+
+func Test_Access_ListingsAreRestricted(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	visibleToBob := mock.MatchedBy(func(filter domain.FileFilter) bool {
+		return filter.VisibleTo != nil && filter.VisibleTo.ID == "bob"
+	})
+	mockRepo.On("List", mock.Anything, visibleToBob, mock.Anything).Return(&domain.FileList{}, nil)
+
+	_, err := uc.ListFiles(asPrincipal("bob"), domain.FileFilter{}, domain.ListOptions{})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_Access_UploadUnderOthersFilename(t *testing.T) {
+	mockRepo, uc := newAccessTestUseCase(t)
+	mockRepo.On("GetUsage", mock.Anything, "mallory").Return(&domain.Usage{OwnerID: "mallory"}, nil)
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, "mallory", "reports/q3.pdf").Return(0, domain.ErrNotFound)
+
+	file, err := uc.UploadFile(asPrincipal("mallory"), domain.FileUpload{Filename: "/reports/q3.pdf"}, strings.NewReader("mine"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "mallory", file.OwnerID)
+	mockRepo.AssertNotCalled(t, "GetByFileName", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetPermission", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test_Access_GrantsStayWithTheirOwner trashes a shared file, uploads
+// another one under its name as someone else, and restores the first one:
+// neither the grant nor the versions of one file carry over to the other.
+func Test_Access_GrantsStayWithTheirOwner(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		TrashRetention: time.Hour,
+	})
+	aliceFile := &domain.File{ID: "alice-uuid", Filename: "report.pdf", OwnerID: "alice"}
+	carol := domain.Accessor{ID: "carol"}
+	mockRepo.On("GetByID", mock.Anything, "alice-uuid").Return(aliceFile, nil)
+	mockRepo.On("PutGrant", mock.Anything, mock.MatchedBy(func(grant *domain.Grant) bool {
+		return grant.OwnerID == "alice" && grant.Filename == "report.pdf"
+	})).Return(nil)
+	mockRepo.On("MoveToTrash", mock.Anything, "alice-uuid").Return(nil)
+
+	_, err := uc.ShareFile(asPrincipal("alice"), "alice-uuid", domain.Grantee{Type: domain.GranteeUser, ID: "carol"}, domain.PermissionRead)
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteFile(asPrincipal("alice"), "alice-uuid"))
+
+	mockRepo.On("GetUsage", mock.Anything, "bob").Return(&domain.Usage{OwnerID: "bob"}, nil)
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, "bob", "report.pdf").Return(0, domain.ErrNotFound)
+	bobFile, err := uc.UploadFile(asPrincipal("bob"), domain.FileUpload{Filename: "report.pdf"}, strings.NewReader("bob's"))
+	require.NoError(t, err)
+	require.Equal(t, "bob", bobFile.OwnerID)
+
+	mockRepo.On("GetByID", mock.Anything, bobFile.ID).Return(bobFile, nil)
+	mockRepo.On("GetPermission", mock.Anything, "bob", "report.pdf", carol).Return(domain.PermissionNone, nil)
+	_, _, err = uc.DownLoadFile(asPrincipal("carol"), bobFile.ID, domain.ByteRange{Length: -1})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	mockRepo.On("GetTrashedByID", mock.Anything, "alice-uuid").Return(aliceFile, nil)
+	mockRepo.On("RestoreFromTrash", mock.Anything, "alice-uuid").Return(nil)
+	_, err = uc.RestoreFile(asPrincipal("alice"), "alice-uuid")
+	require.NoError(t, err)
+
+	mockRepo.On("ListVersions", mock.Anything, "bob", "report.pdf").Return([]domain.File{*bobFile}, nil)
+	versions, err := uc.ListVersions(asPrincipal("bob"), "report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.File{*bobFile}, versions)
+
+	mockRepo.AssertNotCalled(t, "GetPermission", mock.Anything, "alice", "report.pdf", carol)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_Access_FoldersBelongToTheirCreator(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+	mockRepo.On("CreateFolder", mock.Anything, "alice", "projects/alpha").Return(&domain.Folder{Path: "projects/alpha", OwnerID: "alice"}, nil)
+	mockRepo.On("CreateFolder", mock.Anything, "mallory", "projects/alpha/junk").Return(nil, domain.ErrPermissionDenied)
+
+	folder, err := uc.CreateFolder(asPrincipal("alice"), "/projects/alpha/")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", folder.OwnerID)
+
+	_, err = uc.CreateFolder(asPrincipal("mallory"), "projects/alpha/junk")
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionPolicy decides how uploads are compressed at rest. Only
+// content types matching ContentTypes are compressed, or all of them when
+// the list is empty, and uploads declared smaller than MinSize are stored
+// as is because compression would gain little.
+type CompressionPolicy struct {
+	Algorithm    string
+	ContentTypes []string
+	MinSize      int64
+}
+
+func (p CompressionPolicy) Validate() error {
+	switch p.Algorithm {
+	case "", domain.CompressionNone, domain.CompressionGzip, domain.CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unknown compression algorithm %q", p.Algorithm)
+	}
+}
+
+// algorithmFor returns the compression to store an upload of the given
+// normalized content type with.
+func (p CompressionPolicy) algorithmFor(contentType string, declaredSize *int64) string {
+	if p.Algorithm == "" || p.Algorithm == domain.CompressionNone {
+		return domain.CompressionNone
+	}
+	if declaredSize != nil && *declaredSize < p.MinSize {
+		return domain.CompressionNone
+	}
+	if len(p.ContentTypes) == 0 {
+		return p.Algorithm
+	}
+	for _, pattern := range p.ContentTypes {
+		if matchContentType(pattern, contentType) {
+			return p.Algorithm
+		}
+	}
+
+	return domain.CompressionNone
+}
+
+func newCompressor(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case "", domain.CompressionNone:
+		return nopWriteCloser{w}, nil
+	case domain.CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case domain.CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+func newDecompressor(algorithm string, r io.Reader) (io.ReadCloser, error) {
+	switch algorithm {
+	case "", domain.CompressionNone:
+		return io.NopCloser(r), nil
+	case domain.CompressionGzip:
+		return gzip.NewReader(r)
+	case domain.CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+
+	return len(p), nil
+}
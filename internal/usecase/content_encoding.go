@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/encryption"
+)
+
+// Content is stored compressed first and then encrypted, as recorded on
+// the file. These helpers apply and undo both steps.
+
+type encoder struct {
+	compressor io.WriteCloser
+	encrypter  io.WriteCloser
+}
+
+// newEncoder returns a writer that compresses and, given a data key,
+// encrypts everything written to it into w. Closing it flushes both steps
+// but does not close w.
+func newEncoder(w io.Writer, compression string, dataKey []byte) (io.WriteCloser, error) {
+	var encrypter io.WriteCloser = nopWriteCloser{w}
+	if dataKey != nil {
+		var err error
+		if encrypter, err = encryption.NewWriter(w, dataKey); err != nil {
+			return nil, err
+		}
+	}
+
+	compressor, err := newCompressor(compression, encrypter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encoder{
+		compressor: compressor,
+		encrypter:  encrypter,
+	}, nil
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+	return e.compressor.Write(p)
+}
+
+func (e *encoder) Close() error {
+	if err := e.compressor.Close(); err != nil {
+		return err
+	}
+
+	return e.encrypter.Close()
+}
+
+// encodeReader returns the encoded content of r. The caller must close the
+// result to stop the encoding goroutine if it does not read to EOF.
+func encodeReader(r io.Reader, compression string, dataKey []byte) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	encoder, err := newEncoder(pw, compression, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		_, err := io.Copy(encoder, r)
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+// newDataKey returns a fresh data key for a file, or nothing when
+// encryption is disabled.
+func (uc *fileUseCase) newDataKey() (keyID string, dataKey, wrappedKey []byte, err error) {
+	if uc.keyring == nil {
+		return "", nil, nil, nil
+	}
+
+	return uc.keyring.NewDataKey()
+}
+
+// openDecrypted opens the stored content of a file decrypted, starting at
+// or before offset, which is only honoured for uncompressed files. It
+// returns the position of the first byte read.
+func (uc *fileUseCase) openDecrypted(ctx context.Context, file *domain.File, offset int64) (io.ReadCloser, int64, error) {
+	if file.KeyID == "" {
+		stored, err := uc.blobs.Get(ctx, file.Path, offset, -1)
+		return stored, offset, err
+	}
+
+	if uc.keyring == nil {
+		return nil, 0, errors.New("file is encrypted but no master keys are configured")
+	}
+	dataKey, err := uc.keyring.UnwrapKey(file.KeyID, file.WrappedKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	segment, storedOffset := encryption.SegmentStart(offset)
+	stored, err := uc.blobs.Get(ctx, file.Path, storedOffset, -1)
+	if err != nil {
+		return nil, 0, err
+	}
+	decrypted, err := encryption.NewReader(stored, dataKey, segment)
+	if err != nil {
+		stored.Close()
+		return nil, 0, err
+	}
+
+	return &readCloser{Reader: decrypted, Closer: stored}, segment * encryption.SegmentSize, nil
+}
+
+// openDecoded opens the content of a file decrypted and decompressed,
+// starting at offset.
+func (uc *fileUseCase) openDecoded(ctx context.Context, file *domain.File, offset int64) (io.ReadCloser, error) {
+	start := offset
+	if isCompressed(file) {
+		// Compressed content can only be read from the beginning.
+		start = 0
+	}
+
+	decrypted, position, err := uc.openDecrypted(ctx, file, start)
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed, err := newDecompressor(file.Compression, decrypted)
+	if err != nil {
+		decrypted.Close()
+		return nil, err
+	}
+	reader := &readCloser{
+		Reader: decompressed,
+		Closer: closerFunc(func() error {
+			decompressed.Close()
+			return decrypted.Close()
+		}),
+	}
+
+	if _, err := io.CopyN(io.Discard, reader, offset-position); err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+func isCompressed(file *domain.File) bool {
+	return file.Compression != "" && file.Compression != domain.CompressionNone
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
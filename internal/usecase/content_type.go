@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"mime"
+	"strings"
+)
+
+const defaultContentType = "application/octet-stream"
+
+// ContentTypePolicy decides which MIME types may be stored. Patterns are
+// either exact types ("image/png") or a type with a wildcard subtype
+// ("image/*"). Denied patterns win over allowed ones, and an empty allow
+// list allows everything that is not denied.
+type ContentTypePolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+func (p ContentTypePolicy) Allows(contentType string) bool {
+	mediaType := normalizeContentType(contentType)
+
+	for _, pattern := range p.Denied {
+		if matchContentType(pattern, mediaType) {
+			return false
+		}
+	}
+	if len(p.Allowed) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allowed {
+		if matchContentType(pattern, mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeContentType lowercases the media type and drops parameters such
+// as charset, so "Text/Plain; charset=utf-8" becomes "text/plain".
+func normalizeContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(contentType, ";")
+	}
+
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+func matchContentType(pattern, mediaType string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "*" || pattern == "*/*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+
+	return pattern == mediaType
+}
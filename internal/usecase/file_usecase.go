@@ -1,84 +1,400 @@
 package usecase
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"io"
+	"log"
+	"net/http"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/encryption"
 	"github.com/grpc-file-storage-go/internal/repository"
+	"github.com/grpc-file-storage-go/internal/storage"
 
 	"github.com/google/uuid"
 )
 
 type fileUseCase struct {
-	repo        repository.FileRepository
-	storagePath string
+	repo             repository.FileRepository
+	blobs            storage.BlobStore
+	contentTypes     ContentTypePolicy
+	compression      CompressionPolicy
+	contentAddressed bool
+	stagingDir       string
+	keyring          encryption.Keyring
+	keepVersions     int
+	trashRetention   time.Duration
+	admins           []string
 }
 
-func NewFileUseCase(repo repository.FileRepository, storagePath string) FileUseCase {
+type FileUseCaseOptions struct {
+	ContentTypes ContentTypePolicy
+	Compression  CompressionPolicy
+	// ContentAddressed stores each distinct content once, keyed by its
+	// SHA-256, and shares it between files. Uploads are staged in
+	// StagingDir while they are hashed.
+	ContentAddressed bool
+	StagingDir       string
+	// Keyring encrypts content at rest. Encryption is disabled if it is nil.
+	Keyring encryption.Keyring
+	// KeepVersions is how many versions of a filename are kept unless set
+	// otherwise for that filename. Zero keeps all of them.
+	KeepVersions int
+	// TrashRetention is how long deleted files stay in the trash, where
+	// they can be restored, before they are purged. Zero deletes files
+	// right away.
+	TrashRetention time.Duration
+	// Admins are the principals that can read and set the quota of
+	// everyone.
+	Admins []string
+}
+
+func NewFileUseCase(repo repository.FileRepository, blobs storage.BlobStore, opts FileUseCaseOptions) FileUseCase {
 	return &fileUseCase{
-		repo:        repo,
-		storagePath: storagePath,
+		repo:             repo,
+		blobs:            blobs,
+		contentTypes:     opts.ContentTypes,
+		compression:      opts.Compression,
+		contentAddressed: opts.ContentAddressed,
+		stagingDir:       opts.StagingDir,
+		keyring:          opts.Keyring,
+		keepVersions:     opts.KeepVersions,
+		trashRetention:   opts.TrashRetention,
+		admins:           opts.Admins,
 	}
 }
 
-func (uc *fileUseCase) UploadFile(ctx context.Context, filename string, data io.Reader) (*domain.File, error) {
-	ext := filepath.Ext(filename)
-	baseName := filename[:len(filename)-len(ext)]
-	uniqueFilename := baseName + "_" + uuid.New().String() + ext
-	filePath := filepath.Join(uc.storagePath, uniqueFilename)
+const (
+	purgeBatchSize = 100
+	// sniffLen is how many bytes http.DetectContentType considers.
+	sniffLen = 512
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// UploadFile stores a file owned by the authenticated caller, if any, as
+// the next version of the caller's file with the same filename. Files of
+// other owners are separate even if they have that filename too. It fails
+// with ErrQuotaExceeded if the caller has no room left for the file.
+func (uc *fileUseCase) UploadFile(ctx context.Context, upload domain.FileUpload, data io.Reader) (*domain.File, error) {
+	ownerID := ownerIDOf(ctx)
+	var quota *quotaReader
+	if ownerID != "" {
+		var err error
+		if quota, err = uc.limitToQuota(ctx, ownerID, upload.DeclaredSize, data); err != nil {
+			return nil, err
+		}
+		if quota != nil {
+			data = quota
+		}
+	}
+
+	file, err := uc.uploadOwnedFile(ctx, upload, ownerID, data)
+	if err != nil && quota != nil && quota.exceeded {
+		// Storage backends do not all keep read errors intact.
+		return nil, quotaExceeded(quota.ownerID)
+	}
+
+	return file, err
+}
 
-	if err := os.MkdirAll(uc.storagePath, 0755); err != nil {
+func (uc *fileUseCase) uploadOwnedFile(ctx context.Context, upload domain.FileUpload, ownerID string, data io.Reader) (*domain.File, error) {
+	file, err := uc.uploadFile(ctx, upload, ownerID, data)
+	if err != nil {
 		return nil, err
 	}
 
-	file, err := os.Create(filePath)
+	// The upload succeeded, so pruning runs to completion even if the
+	// client goes away now.
+	uc.pruneVersions(context.WithoutCancel(ctx), file.OwnerID, file.Filename)
+
+	return file, nil
+}
+
+func (uc *fileUseCase) uploadFile(ctx context.Context, upload domain.FileUpload, ownerID string, data io.Reader) (*domain.File, error) {
+	filename, err := cleanFilename(upload.Filename)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	size, err := io.Copy(file, data)
+	contentType, data, err := uc.resolveContentType(upload.ContentType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The blob is keyed by the file id, so client-supplied names never
+	// reach the storage backend and identical names do not collide.
+	id := uuid.New().String()
+
+	keyID, dataKey, wrappedKey, err := uc.newDataKey()
 	if err != nil {
-		os.Remove(filePath)
 		return nil, err
 	}
 
 	fileMetadata := &domain.File{
-		ID:        uuid.New().String(),
-		Filename:  uniqueFilename,
-		Size:      size,
-		Path:      filePath,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          id,
+		Filename:    filename,
+		StorageName: id,
+		Compression: uc.compression.algorithmFor(contentType, upload.DeclaredSize),
+		ContentType: contentType,
+		Tags:        normalizeTags(upload.Tags),
+		Description: upload.Description,
+		Labels:      upload.Labels,
+		OwnerID:     ownerID,
+		Path:        id,
+		KeyID:       keyID,
+		WrappedKey:  wrappedKey,
+	}
+
+	if uc.contentAddressed {
+		return uc.uploadContentAddressed(ctx, upload, fileMetadata, dataKey, data)
+	}
+
+	sha := sha256.New()
+	crc := crc32.New(crc32cTable)
+	size := &countingWriter{}
+	encoded, err := encodeReader(io.TeeReader(data, io.MultiWriter(sha, crc, size)), fileMetadata.Compression, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Close()
+
+	storedSize, err := uc.blobs.Put(ctx, id, encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMetadata.Size = size.n
+	fileMetadata.StoredSize = storedSize
+	fileMetadata.ChecksumSHA256 = hex.EncodeToString(sha.Sum(nil))
+	fileMetadata.ChecksumCRC32C = crc.Sum32()
+	fileMetadata.CreatedAt = time.Now()
+	fileMetadata.UpdatedAt = time.Now()
+
+	if err := verifyChecksums(upload, fileMetadata); err != nil {
+		uc.discardBlob(id)
+		return nil, err
 	}
 
 	if err := uc.repo.Save(ctx, fileMetadata); err != nil {
-		os.Remove(filePath)
+		uc.discardBlob(id)
+		return nil, err
+	}
+
+	return fileMetadata, nil
+}
+
+// uploadContentAddressed stages data in a temporary file while hashing it,
+// then stores it under its SHA-256 unless an identical blob already exists.
+func (uc *fileUseCase) uploadContentAddressed(ctx context.Context, upload domain.FileUpload, fileMetadata *domain.File, dataKey []byte, data io.Reader) (*domain.File, error) {
+	if err := os.MkdirAll(uc.stagingDir, 0755); err != nil {
+		return nil, err
+	}
+	staged, err := os.CreateTemp(uc.stagingDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(staged.Name())
+	defer staged.Close()
+
+	storedSize := &countingWriter{}
+	encoder, err := newEncoder(io.MultiWriter(staged, storedSize), fileMetadata.Compression, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sha := sha256.New()
+	crc := crc32.New(crc32cTable)
+	size, err := io.Copy(io.MultiWriter(encoder, sha, crc), data)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	checksum := hex.EncodeToString(sha.Sum(nil))
+	fileMetadata.Size = size
+	fileMetadata.StoredSize = storedSize.n
+	fileMetadata.Path = contentAddressedKey(checksum)
+	fileMetadata.ChecksumSHA256 = checksum
+	fileMetadata.ChecksumCRC32C = crc.Sum32()
+	fileMetadata.BlobSHA256 = checksum
+	fileMetadata.CreatedAt = time.Now()
+	fileMetadata.UpdatedAt = time.Now()
+
+	if err := verifyChecksums(upload, fileMetadata); err != nil {
+		return nil, err
+	}
+
+	err = uc.repo.SaveWithBlobRef(ctx, fileMetadata, func(ctx context.Context) error {
+		if _, err := staged.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		_, err := uc.blobs.Put(ctx, fileMetadata.Path, staged)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return fileMetadata, nil
 }
 
-func (uc *fileUseCase) DownLoadFile(ctx context.Context, filename string) (*domain.File, io.Reader, error) {
-	file, err := uc.repo.GetByFileName(ctx, filename)
+// contentAddressedKey spreads blobs over directories named after the first
+// two hex digits of their checksum.
+func contentAddressedKey(checksum string) string {
+	return "sha256/" + checksum[:2] + "/" + checksum
+}
+
+func (uc *fileUseCase) DownLoadFile(ctx context.Context, fileID string, byteRange domain.ByteRange) (*domain.File, io.Reader, error) {
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionRead); err != nil {
+		return nil, nil, err
+	}
+
+	length, err := resolveRange(byteRange, file.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isCompressed(file) && file.KeyID == "" {
+		reader, err := uc.blobs.Get(ctx, file.Path, byteRange.Offset, length)
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, reader, nil
+	}
+	if length == 0 {
+		return file, io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	reader, err := uc.openDecoded(ctx, file, byteRange.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, &readCloser{Reader: io.LimitReader(reader, length), Closer: reader}, nil
+}
+
+// DownloadStored returns the whole content of a file as stored, decrypted
+// but not decompressed.
+func (uc *fileUseCase) DownloadStored(ctx context.Context, fileID string) (*domain.File, io.Reader, error) {
+	file, err := uc.repo.GetByID(ctx, fileID)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := uc.authorize(ctx, file, domain.PermissionRead); err != nil {
+		return nil, nil, err
+	}
 
-	fileReader, err := os.Open(file.Path)
+	reader, _, err := uc.openDecrypted(ctx, file, 0)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return file, fileReader, nil
+	return file, reader, nil
+}
+
+func (uc *fileUseCase) ListFiles(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize < 1 || opts.PageSize > 100 {
+		opts.PageSize = 20
+	}
+	filter.VisibleTo = accessorOf(ctx)
+
+	return uc.repo.List(ctx, filter, opts)
+}
+
+func verifyChecksums(upload domain.FileUpload, file *domain.File) error {
+	if upload.ExpectedSHA256 != "" && upload.ExpectedSHA256 != file.ChecksumSHA256 {
+		return fmt.Errorf("%w: expected sha256 %s, got %s",
+			domain.ErrChecksumMismatch, upload.ExpectedSHA256, file.ChecksumSHA256)
+	}
+	if upload.ExpectedCRC32C != nil && *upload.ExpectedCRC32C != file.ChecksumCRC32C {
+		return fmt.Errorf("%w: expected crc32c %d, got %d",
+			domain.ErrChecksumMismatch, *upload.ExpectedCRC32C, file.ChecksumCRC32C)
+	}
+
+	return nil
+}
+
+func (uc *fileUseCase) GetFileByID(ctx context.Context, id string) (*domain.File, error) {
+	file, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// GetFileByName returns the latest version of the caller's file with the
+// given filename.
+func (uc *fileUseCase) GetFileByName(ctx context.Context, filename string) (*domain.File, error) {
+	file, err := uc.repo.GetByFileName(ctx, ownerIDOf(ctx), filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// DeleteFile moves a single version of a file to the trash, or deletes it
+// right away if there is no trash. The previous version becomes the latest.
+func (uc *fileUseCase) DeleteFile(ctx context.Context, fileID string) error {
+	if uc.trashRetention > 0 {
+		if err := uc.authorizeID(ctx, fileID, domain.PermissionWrite); err != nil {
+			return err
+		}
+		return uc.repo.MoveToTrash(ctx, fileID)
+	}
+
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionWrite); err != nil {
+		return err
+	}
+
+	return uc.deleteFile(ctx, file)
+}
+
+func (uc *fileUseCase) UpdateFileMetadata(ctx context.Context, fileID string, update domain.MetadataUpdate) (*domain.File, error) {
+	if err := uc.authorizeID(ctx, fileID, domain.PermissionWrite); err != nil {
+		return nil, err
+	}
+	if update.Tags != nil {
+		update.Tags = normalizeTags(update.Tags)
+	}
+
+	return uc.repo.UpdateMetadata(ctx, fileID, update)
 }
 
-func (uc *fileUseCase) ListFiles(ctx context.Context, page, pageSize int) (*domain.FileList, error) {
+func (uc *fileUseCase) SearchFiles(ctx context.Context, query string, page, pageSize int) ([]domain.SearchResult, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -86,5 +402,360 @@ func (uc *fileUseCase) ListFiles(ctx context.Context, page, pageSize int) (*doma
 		pageSize = 20
 	}
 
-	return uc.repo.List(ctx, page, pageSize)
+	return uc.repo.Search(ctx, strings.TrimSpace(query), accessorOf(ctx), page, pageSize)
+}
+
+func (uc *fileUseCase) ListTrash(ctx context.Context, page, pageSize int) (*domain.FileList, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return uc.repo.ListTrash(ctx, accessorOf(ctx), page, pageSize)
+}
+
+func (uc *fileUseCase) RestoreFile(ctx context.Context, fileID string) (*domain.File, error) {
+	if accessorOf(ctx) != nil {
+		file, err := uc.repo.GetTrashedByID(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if err := uc.authorize(ctx, file, domain.PermissionWrite); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.repo.RestoreFromTrash(ctx, fileID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetByID(ctx, fileID)
+}
+
+// PurgeFile permanently deletes a file in the trash.
+func (uc *fileUseCase) PurgeFile(ctx context.Context, fileID string) error {
+	file, err := uc.repo.GetTrashedByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionWrite); err != nil {
+		return err
+	}
+
+	return uc.deleteFile(ctx, file)
+}
+
+// PurgeTrash permanently deletes files that have been in the trash for
+// longer than the trash retention.
+func (uc *fileUseCase) PurgeTrash(ctx context.Context) (int, error) {
+	if uc.trashRetention <= 0 {
+		return 0, nil
+	}
+
+	files, err := uc.repo.ListTrashedBefore(ctx, time.Now().Add(-uc.trashRetention), purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range files {
+		if err := uc.deleteFile(ctx, &files[i]); err != nil {
+			log.Printf("failed to purge trashed file %s: %v", files[i].ID, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (uc *fileUseCase) deleteFile(ctx context.Context, file *domain.File) error {
+	if err := uc.repo.MarkDeleted(ctx, file.ID); err != nil {
+		return err
+	}
+
+	return uc.removeFile(ctx, file)
+}
+
+// ListVersions lists the versions of the caller's file with the given
+// filename, newest first.
+func (uc *fileUseCase) ListVersions(ctx context.Context, filename string) ([]domain.File, error) {
+	versions, err := uc.repo.ListVersions(ctx, ownerIDOf(ctx), filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("file %s: %w", filename, domain.ErrNotFound)
+	}
+	// All versions share the owner and grants of the file.
+	if err := uc.authorize(ctx, &versions[0], domain.PermissionRead); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion makes the content of an older version the latest one by
+// uploading it again as a new version, owned by the owner of the old one.
+func (uc *fileUseCase) RestoreVersion(ctx context.Context, fileID string) (*domain.File, error) {
+	version, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, version, domain.PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	content, err := uc.openDecoded(ctx, version, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	return uc.uploadOwnedFile(ctx, domain.FileUpload{
+		Filename:       version.Filename,
+		ContentType:    version.ContentType,
+		Tags:           version.Tags,
+		Description:    version.Description,
+		Labels:         version.Labels,
+		DeclaredSize:   &version.Size,
+		ExpectedSHA256: version.ChecksumSHA256,
+	}, version.OwnerID, content)
+}
+
+// SetVersionRetention sets how many versions of the caller's file with the
+// given filename are kept.
+func (uc *fileUseCase) SetVersionRetention(ctx context.Context, filename string, keepVersions int) error {
+	ownerID := ownerIDOf(ctx)
+	if err := uc.repo.SetVersionRetention(ctx, ownerID, filename, keepVersions); err != nil {
+		return err
+	}
+
+	uc.pruneVersions(ctx, ownerID, filename)
+
+	return nil
+}
+
+// CreateFolder creates a folder along with any missing parent folders,
+// owned by the caller. Folders with an owner only hold files and folders
+// of their owner.
+func (uc *fileUseCase) CreateFolder(ctx context.Context, path string) (*domain.Folder, error) {
+	path, err := cleanFilename(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.CreateFolder(ctx, ownerIDOf(ctx), path)
+}
+
+// ListFolder lists the direct children of a folder, "" being the root.
+func (uc *fileUseCase) ListFolder(ctx context.Context, path string, after *domain.FolderCursor, pageSize int) (*domain.FolderListing, error) {
+	path, err := domain.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return uc.repo.ListFolder(ctx, path, accessorOf(ctx), after, pageSize)
+}
+
+// MoveFile moves all versions of a file into folder, "" being the root,
+// keeping its name.
+func (uc *fileUseCase) MoveFile(ctx context.Context, fileID, folder string) (*domain.File, error) {
+	folder, err := domain.CleanPath(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	return uc.renameFile(ctx, file, domain.JoinPath(folder, domain.BaseName(file.Filename)))
+}
+
+// RenameFile renames all versions of a file within its folder.
+func (uc *fileUseCase) RenameFile(ctx context.Context, fileID, name string) (*domain.File, error) {
+	if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: name %q contains a slash", domain.ErrInvalidPath, name)
+	}
+	name, err := cleanFilename(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := uc.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(ctx, file, domain.PermissionWrite); err != nil {
+		return nil, err
+	}
+
+	return uc.renameFile(ctx, file, domain.JoinPath(domain.ParentPath(file.Filename), name))
+}
+
+func (uc *fileUseCase) renameFile(ctx context.Context, file *domain.File, filename string) (*domain.File, error) {
+	if filename == file.Filename {
+		return file, nil
+	}
+	// The new name can push a file that fit in its old folder over the
+	// length limit.
+	if utf8.RuneCountInString(filename) > domain.MaxPathLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", domain.ErrInvalidPath, domain.MaxPathLength)
+	}
+	if err := uc.repo.Rename(ctx, file.OwnerID, file.Filename, filename); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetByID(ctx, file.ID)
+}
+
+// cleanFilename cleans the path of a file or folder, which cannot be the
+// root.
+func cleanFilename(filename string) (string, error) {
+	cleaned, err := domain.CleanPath(filename)
+	if err != nil {
+		return "", err
+	}
+	if cleaned == "" {
+		return "", fmt.Errorf("%w: %q names the root folder", domain.ErrInvalidPath, filename)
+	}
+
+	return cleaned, nil
+}
+
+// pruneVersions deletes the versions of the file filename of ownerID beyond
+// its retention limit. Failures are only logged; the next upload retries
+// them.
+func (uc *fileUseCase) pruneVersions(ctx context.Context, ownerID, filename string) {
+	keepVersions, err := uc.repo.GetVersionRetention(ctx, ownerID, filename)
+	if errors.Is(err, domain.ErrNotFound) {
+		keepVersions = uc.keepVersions
+	} else if err != nil {
+		log.Printf("failed to get version retention of %s: %v", filename, err)
+		return
+	}
+	if keepVersions <= 0 {
+		return
+	}
+
+	versions, err := uc.repo.ListVersions(ctx, ownerID, filename)
+	if err != nil {
+		log.Printf("failed to list versions of %s: %v", filename, err)
+		return
+	}
+	for i := keepVersions; i < len(versions); i++ {
+		if err := uc.deleteFile(ctx, &versions[i]); err != nil {
+			log.Printf("failed to prune version %d of %s: %v", versions[i].Version, filename, err)
+		}
+	}
+}
+
+// PurgePendingDeletes finishes deletes that were interrupted after the row
+// was marked but before the blob or the row were removed.
+func (uc *fileUseCase) PurgePendingDeletes(ctx context.Context) (int, error) {
+	files, err := uc.repo.ListPendingDeletes(ctx, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range files {
+		if err := uc.removeFile(ctx, &files[i]); err != nil {
+			log.Printf("failed to purge file %s: %v", files[i].ID, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// PurgeUnreferencedBlobs removes content-addressed blobs that the last file
+// using them released.
+func (uc *fileUseCase) PurgeUnreferencedBlobs(ctx context.Context) (int, error) {
+	return uc.repo.DeleteUnreferencedBlobs(ctx, purgeBatchSize, uc.blobs.Delete)
+}
+
+func (uc *fileUseCase) removeFile(ctx context.Context, file *domain.File) error {
+	if file.BlobSHA256 != "" {
+		return uc.repo.DeleteWithBlobRef(ctx, file.ID)
+	}
+
+	if err := uc.blobs.Delete(ctx, file.Path); err != nil {
+		return err
+	}
+
+	return uc.repo.Delete(ctx, file.ID)
+}
+
+// discardBlob removes a blob that was stored for an upload that failed
+// afterwards. It must not depend on the request context, which may already
+// be cancelled.
+func (uc *fileUseCase) discardBlob(key string) {
+	if err := uc.blobs.Delete(context.Background(), key); err != nil {
+		log.Printf("failed to discard blob %s: %v", key, err)
+	}
+}
+
+// resolveContentType returns the normalized content type of an upload,
+// sniffing it from the first bytes of data when the client sent none. The
+// returned reader must be used instead of data afterwards.
+func (uc *fileUseCase) resolveContentType(declared string, data io.Reader) (string, io.Reader, error) {
+	contentType := normalizeContentType(declared)
+	if contentType == "" {
+		buffered := bufio.NewReaderSize(data, sniffLen)
+		head, err := buffered.Peek(sniffLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return "", nil, err
+		}
+		contentType = normalizeContentType(http.DetectContentType(head))
+		data = buffered
+	}
+
+	if !uc.contentTypes.Allows(contentType) {
+		return "", nil, fmt.Errorf("%w: %s", domain.ErrContentTypeDenied, contentType)
+	}
+
+	return contentType, data, nil
+}
+
+// resolveRange validates byteRange against a file of the given size and
+// returns the number of bytes to read.
+func resolveRange(byteRange domain.ByteRange, size int64) (int64, error) {
+	if byteRange.Offset < 0 || byteRange.Offset > size {
+		return 0, fmt.Errorf("%w: offset %d, size %d", domain.ErrInvalidRange, byteRange.Offset, size)
+	}
+
+	remaining := size - byteRange.Offset
+	if byteRange.Length < 0 {
+		return remaining, nil
+	}
+	if byteRange.Length > remaining {
+		return 0, fmt.Errorf("%w: offset %d, length %d, size %d",
+			domain.ErrInvalidRange, byteRange.Offset, byteRange.Length, size)
+	}
+
+	return byteRange.Length, nil
+}
+
+// normalizeTags trims tags and drops empty and repeated ones.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
 }
@@ -0,0 +1,919 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/auth"
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/encryption"
+	"github.com/grpc-file-storage-go/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockFileRepository struct {
+	mock.Mock
+}
+
+func (m *MockFileRepository) Save(ctx context.Context, file *domain.File) error {
+	args := m.Called(ctx, file)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) SaveWithBlobRef(ctx context.Context, file *domain.File, putBlob func(ctx context.Context) error) error {
+	args := m.Called(ctx, file, putBlob)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) GetByFileName(ctx context.Context, ownerID, fileName string) (*domain.File, error) {
+	args := m.Called(ctx, ownerID, fileName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) GetByID(ctx context.Context, id string) (*domain.File, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) List(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error) {
+	args := m.Called(ctx, filter, opts)
+
+	return args.Get(0).(*domain.FileList), args.Error(1)
+}
+
+func (m *MockFileRepository) MarkDeleted(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) DeleteWithBlobRef(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) DeleteUnreferencedBlobs(ctx context.Context, limit int, removeBlob func(ctx context.Context, path string) error) (int, error) {
+	args := m.Called(ctx, limit, removeBlob)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFileRepository) ListVersions(ctx context.Context, ownerID, filename string) ([]domain.File, error) {
+	args := m.Called(ctx, ownerID, filename)
+
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) GetVersionRetention(ctx context.Context, ownerID, filename string) (int, error) {
+	args := m.Called(ctx, ownerID, filename)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFileRepository) SetVersionRetention(ctx context.Context, ownerID, filename string, keepVersions int) error {
+	args := m.Called(ctx, ownerID, filename, keepVersions)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) UpdateMetadata(ctx context.Context, id string, update domain.MetadataUpdate) (*domain.File, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) Search(ctx context.Context, query string, visibleTo *domain.Accessor, page, pageSize int) ([]domain.SearchResult, error) {
+	args := m.Called(ctx, query, visibleTo, page, pageSize)
+
+	return args.Get(0).([]domain.SearchResult), args.Error(1)
+}
+
+func (m *MockFileRepository) MoveToTrash(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) RestoreFromTrash(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) GetTrashedByID(ctx context.Context, id string) (*domain.File, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) ListTrash(ctx context.Context, visibleTo *domain.Accessor, page, pageSize int) (*domain.FileList, error) {
+	args := m.Called(ctx, visibleTo, page, pageSize)
+
+	return args.Get(0).(*domain.FileList), args.Error(1)
+}
+
+func (m *MockFileRepository) ListTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.File, error) {
+	args := m.Called(ctx, before, limit)
+
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
+func (m *MockFileRepository) CreateFolder(ctx context.Context, ownerID, path string) (*domain.Folder, error) {
+	args := m.Called(ctx, ownerID, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Folder), args.Error(1)
+}
+
+func (m *MockFileRepository) ListFolder(ctx context.Context, path string, visibleTo *domain.Accessor, after *domain.FolderCursor, limit int) (*domain.FolderListing, error) {
+	args := m.Called(ctx, path, visibleTo, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.FolderListing), args.Error(1)
+}
+
+func (m *MockFileRepository) Rename(ctx context.Context, ownerID, filename, newFilename string) error {
+	args := m.Called(ctx, ownerID, filename, newFilename)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) GetPermission(ctx context.Context, ownerID, filename string, accessor domain.Accessor) (domain.Permission, error) {
+	args := m.Called(ctx, ownerID, filename, accessor)
+
+	return args.Get(0).(domain.Permission), args.Error(1)
+}
+
+func (m *MockFileRepository) PutGrant(ctx context.Context, grant *domain.Grant) error {
+	args := m.Called(ctx, grant)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) DeleteGrant(ctx context.Context, ownerID, filename string, grantee domain.Grantee) error {
+	args := m.Called(ctx, ownerID, filename, grantee)
+
+	return args.Error(0)
+}
+
+func (m *MockFileRepository) ListGrants(ctx context.Context, ownerID, filename string) ([]domain.Grant, error) {
+	args := m.Called(ctx, ownerID, filename)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]domain.Grant), args.Error(1)
+}
+
+func (m *MockFileRepository) GetUsage(ctx context.Context, ownerID string) (*domain.Usage, error) {
+	args := m.Called(ctx, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Usage), args.Error(1)
+}
+
+func (m *MockFileRepository) SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error) {
+	args := m.Called(ctx, ownerID, quota)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.Usage), args.Error(1)
+}
+
+func (m *MockFileRepository) ListPendingDeletes(ctx context.Context, limit int) ([]domain.File, error) {
+	args := m.Called(ctx, limit)
+
+	return args.Get(0).([]domain.File), args.Error(1)
+}
+
+func Test_UploadFile_ComputesChecksums(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+
+	file, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "hello.txt"}, strings.NewReader("hello world"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello.txt", file.Filename)
+	assert.Equal(t, file.ID, file.StorageName)
+	assert.Equal(t, int64(11), file.Size)
+	assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", file.ChecksumSHA256)
+	assert.Equal(t, uint32(0xc99465aa), file.ChecksumCRC32C)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_UploadFile_ChecksumMismatchRemovesFile(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{})
+
+	upload := domain.FileUpload{
+		Filename:       "hello.txt",
+		ExpectedSHA256: strings.Repeat("0", 64),
+	}
+	_, err := uc.UploadFile(context.Background(), upload, strings.NewReader("hello world"))
+
+	assert.True(t, errors.Is(err, domain.ErrChecksumMismatch))
+	entries, err := os.ReadDir(storagePath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	mockRepo.AssertNotCalled(t, "Save")
+}
+
+func Test_DownLoadFile_Range(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	uploaded, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "hello.txt"}, strings.NewReader("hello world"))
+	require.NoError(t, err)
+	mockRepo.On("GetByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+
+	_, reader, err := uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 6, Length: 3})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "wor", string(data))
+	reader.(io.Closer).Close()
+
+	_, reader, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 6, Length: -1})
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+	reader.(io.Closer).Close()
+
+	_, _, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 6, Length: 10})
+	assert.True(t, errors.Is(err, domain.ErrInvalidRange))
+
+	_, _, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 12, Length: -1})
+	assert.True(t, errors.Is(err, domain.ErrInvalidRange))
+}
+
+func Test_DeleteFile_RemovesBlobAfterMarkingRow(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	uploaded, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "hello.txt"}, strings.NewReader("hello world"))
+	require.NoError(t, err)
+
+	mockRepo.On("GetByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+	mockRepo.On("MarkDeleted", mock.Anything, uploaded.ID).Return(nil).Run(func(mock.Arguments) {
+		_, err := os.Stat(filepath.Join(storagePath, uploaded.Path))
+		assert.NoError(t, err, "blob must still exist when the row is marked")
+	})
+	mockRepo.On("Delete", mock.Anything, uploaded.ID).Return(nil)
+
+	err = uc.DeleteFile(context.Background(), uploaded.ID)
+
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(storagePath, uploaded.Path))
+	assert.True(t, os.IsNotExist(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_DeleteFile_NotFound(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	mockRepo.On("GetByID", mock.Anything, "missing-uuid").Return(nil, domain.ErrNotFound)
+
+	err := uc.DeleteFile(context.Background(), "missing-uuid")
+
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	mockRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything)
+}
+
+func Test_PurgePendingDeletes_RemovesMissingBlobRows(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	pending := []domain.File{{ID: "gone", Path: "nonexistent-blob"}}
+	mockRepo.On("ListPendingDeletes", mock.Anything, purgeBatchSize).Return(pending, nil)
+	mockRepo.On("Delete", mock.Anything, "gone").Return(nil)
+
+	purged, err := uc.PurgePendingDeletes(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_UploadFile_SniffsContentType(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 600)
+	file, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "image"}, strings.NewReader(png))
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", file.ContentType)
+	assert.Equal(t, int64(len(png)), file.Size, "sniffed bytes must still be stored")
+}
+
+func Test_UploadFile_DeniedContentType(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		ContentTypes: ContentTypePolicy{
+			Allowed: []string{"image/*", "text/plain"},
+			Denied:  []string{"image/svg+xml"},
+		},
+	})
+
+	_, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "logo.svg", ContentType: "image/svg+xml"}, strings.NewReader("<svg/>"))
+	assert.True(t, errors.Is(err, domain.ErrContentTypeDenied))
+
+	_, err = uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "data.json", ContentType: "application/json"}, strings.NewReader("{}"))
+	assert.True(t, errors.Is(err, domain.ErrContentTypeDenied))
+
+	entries, err := os.ReadDir(storagePath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	mockRepo.AssertNotCalled(t, "Save")
+}
+
+func Test_UploadFile_ContentAddressedDeduplicates(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		ContentAddressed: true,
+		StagingDir:       filepath.Join(storagePath, ".staging"),
+	})
+
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	refs := make(map[string]int)
+	mockRepo.On("SaveWithBlobRef", mock.Anything, mock.AnythingOfType("*domain.File"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			file := args.Get(1).(*domain.File)
+			refs[file.BlobSHA256]++
+			if refs[file.BlobSHA256] == 1 {
+				putBlob := args.Get(2).(func(ctx context.Context) error)
+				require.NoError(t, putBlob(context.Background()))
+			}
+		}).
+		Return(nil)
+
+	first, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "a.txt"}, strings.NewReader("same content"))
+	require.NoError(t, err)
+	second, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "b.txt"}, strings.NewReader("same content"))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, first.Path, second.Path)
+	assert.Equal(t, first.ChecksumSHA256, first.BlobSHA256)
+	assert.Equal(t, "sha256/"+first.ChecksumSHA256[:2]+"/"+first.ChecksumSHA256, first.Path)
+
+	data, err := os.ReadFile(filepath.Join(storagePath, filepath.FromSlash(first.Path)))
+	require.NoError(t, err)
+	assert.Equal(t, "same content", string(data))
+
+	staged, err := os.ReadDir(filepath.Join(storagePath, ".staging"))
+	require.NoError(t, err)
+	assert.Empty(t, staged)
+}
+
+func Test_UploadFile_ContentAddressedChecksumMismatch(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		ContentAddressed: true,
+		StagingDir:       filepath.Join(storagePath, ".staging"),
+	})
+
+	_, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "a.txt", ExpectedSHA256: strings.Repeat("0", 64)}, strings.NewReader("content"))
+	assert.True(t, errors.Is(err, domain.ErrChecksumMismatch))
+	mockRepo.AssertNotCalled(t, "SaveWithBlobRef")
+}
+
+func Test_DeleteFile_ReleasesBlobRef(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	blobs := storage.NewLocalBlobStore(storagePath)
+	uc := NewFileUseCase(mockRepo, blobs, FileUseCaseOptions{})
+
+	_, err := blobs.Put(context.Background(), "sha256/ab/abc", strings.NewReader("shared"))
+	require.NoError(t, err)
+
+	file := &domain.File{ID: "id-1", Path: "sha256/ab/abc", BlobSHA256: "abc"}
+	mockRepo.On("GetByID", mock.Anything, "id-1").Return(file, nil)
+	mockRepo.On("MarkDeleted", mock.Anything, "id-1").Return(nil)
+	mockRepo.On("DeleteWithBlobRef", mock.Anything, "id-1").Return(nil)
+
+	require.NoError(t, uc.DeleteFile(context.Background(), "id-1"))
+
+	_, err = blobs.Stat(context.Background(), "sha256/ab/abc")
+	assert.NoError(t, err, "the blob is only removed once its row is gone")
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, "id-1")
+
+	mockRepo.On("DeleteUnreferencedBlobs", mock.Anything, purgeBatchSize, mock.Anything).
+		Run(func(args mock.Arguments) {
+			removeBlob := args.Get(2).(func(ctx context.Context, path string) error)
+			require.NoError(t, removeBlob(context.Background(), file.Path))
+		}).
+		Return(1, nil)
+
+	purged, err := uc.PurgeUnreferencedBlobs(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = blobs.Stat(context.Background(), "sha256/ab/abc")
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+}
+
+func Test_UploadFile_CompressesAtRest(t *testing.T) {
+	content := strings.Repeat("timestamp=2024-01-01 level=info msg=request handled\n", 200)
+
+	for _, algorithm := range []string{domain.CompressionGzip, domain.CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			mockRepo := new(MockFileRepository)
+			storagePath := t.TempDir()
+			uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+				Compression: CompressionPolicy{Algorithm: algorithm, ContentTypes: []string{"text/*"}},
+			})
+
+			mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+			mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+			uploaded, err := uc.UploadFile(context.Background(),
+				domain.FileUpload{Filename: "app.log", ContentType: "text/plain"}, strings.NewReader(content))
+			require.NoError(t, err)
+			mockRepo.On("GetByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+
+			assert.Equal(t, algorithm, uploaded.Compression)
+			assert.Equal(t, int64(len(content)), uploaded.Size)
+			assert.Less(t, uploaded.StoredSize, uploaded.Size)
+			info, err := os.Stat(filepath.Join(storagePath, uploaded.Path))
+			require.NoError(t, err)
+			assert.Equal(t, uploaded.StoredSize, info.Size())
+
+			_, reader, err := uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 10, Length: 5})
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			reader.(io.Closer).Close()
+			assert.Equal(t, content[10:15], string(data))
+
+			_, reader, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Length: -1})
+			require.NoError(t, err)
+			data, err = io.ReadAll(reader)
+			require.NoError(t, err)
+			reader.(io.Closer).Close()
+			assert.Equal(t, content, string(data))
+		})
+	}
+}
+
+func Test_UploadFile_CompressionPolicySkipsUnmatchedUploads(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		Compression: CompressionPolicy{Algorithm: domain.CompressionGzip, ContentTypes: []string{"text/*"}, MinSize: 1024},
+	})
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+
+	file, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "image.png", ContentType: "image/png"}, strings.NewReader("not really a png"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.CompressionNone, file.Compression)
+	assert.Equal(t, file.Size, file.StoredSize)
+
+	small := int64(5)
+	file, err = uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "a.txt", ContentType: "text/plain", DeclaredSize: &small}, strings.NewReader("small"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.CompressionNone, file.Compression)
+}
+
+func newTestKeyring(t *testing.T) encryption.Keyring {
+	t.Helper()
+
+	keyring, err := encryption.NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{7}, encryption.DataKeySize)}, "")
+	require.NoError(t, err)
+
+	return keyring
+}
+
+func Test_UploadFile_EncryptsAtRest(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		Keyring: newTestKeyring(t),
+	})
+
+	content := bytes.Repeat([]byte("secret payroll data "), 10000)
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	uploaded, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "payroll.csv"}, bytes.NewReader(content))
+	require.NoError(t, err)
+	mockRepo.On("GetByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+
+	assert.Equal(t, "k1", uploaded.KeyID)
+	assert.NotEmpty(t, uploaded.WrappedKey)
+	stored, err := os.ReadFile(filepath.Join(storagePath, uploaded.Path))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(stored, []byte("secret payroll")))
+	assert.Equal(t, uploaded.StoredSize, int64(len(stored)))
+
+	offset := int64(encryption.SegmentSize + 123)
+	_, reader, err := uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: offset, Length: 50})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.(io.Closer).Close()
+	assert.Equal(t, content[offset:offset+50], data)
+
+	_, reader, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Length: -1})
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.(io.Closer).Close()
+	assert.Equal(t, content, data)
+}
+
+func Test_DownloadStored_EncryptedAndCompressed(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		Compression: CompressionPolicy{Algorithm: domain.CompressionGzip},
+		Keyring:     newTestKeyring(t),
+	})
+
+	content := strings.Repeat("level=info msg=ok\n", 1000)
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	uploaded, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "app.log", ContentType: "text/plain"}, strings.NewReader(content))
+	require.NoError(t, err)
+	mockRepo.On("GetByID", mock.Anything, uploaded.ID).Return(uploaded, nil)
+
+	_, reader, err := uc.DownloadStored(context.Background(), uploaded.ID)
+	require.NoError(t, err)
+	compressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.(io.Closer).Close()
+
+	decompressed, err := newDecompressor(domain.CompressionGzip, bytes.NewReader(compressed))
+	require.NoError(t, err)
+	data, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	_, reader, err = uc.DownLoadFile(context.Background(), uploaded.ID, domain.ByteRange{Offset: 18, Length: 18})
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.(io.Closer).Close()
+	assert.Equal(t, "level=info msg=ok\n", string(data))
+}
+
+func Test_SetVersionRetention_PrunesOldestVersions(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{})
+
+	var saved []domain.File
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil).Run(func(args mock.Arguments) {
+		file := args.Get(1).(*domain.File)
+		file.Version = len(saved) + 1
+		saved = append([]domain.File{*file}, saved...)
+	})
+	retention := mockRepo.On("GetVersionRetention", mock.Anything, "", "notes.txt").Return(0, domain.ErrNotFound)
+	for _, content := range []string{"one", "two", "three"} {
+		_, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader(content))
+		require.NoError(t, err)
+	}
+	mockRepo.AssertNotCalled(t, "ListVersions", mock.Anything, mock.Anything, mock.Anything)
+
+	retention.Unset()
+	mockRepo.On("SetVersionRetention", mock.Anything, "", "notes.txt", 2).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, "", "notes.txt").Return(2, nil)
+	mockRepo.On("ListVersions", mock.Anything, "", "notes.txt").Return(saved, nil)
+	mockRepo.On("MarkDeleted", mock.Anything, saved[2].ID).Return(nil)
+	mockRepo.On("Delete", mock.Anything, saved[2].ID).Return(nil)
+
+	err := uc.SetVersionRetention(context.Background(), "notes.txt", 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, saved[2].Version)
+	mockRepo.AssertExpectations(t)
+	_, err = os.Stat(filepath.Join(storagePath, saved[2].Path))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(storagePath, saved[1].Path))
+	assert.NoError(t, err)
+}
+
+func Test_RestoreVersion_UploadsOldContentAsNewVersion(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		Compression: CompressionPolicy{Algorithm: domain.CompressionGzip},
+	})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	old, err := uc.UploadFile(context.Background(), domain.FileUpload{
+		Filename:    "notes.txt",
+		ContentType: "text/plain",
+	}, strings.NewReader("first draft"))
+	require.NoError(t, err)
+	mockRepo.On("GetByID", mock.Anything, old.ID).Return(old, nil)
+
+	restored, err := uc.RestoreVersion(context.Background(), old.ID)
+
+	require.NoError(t, err)
+	assert.NotEqual(t, old.ID, restored.ID)
+	assert.Equal(t, old.Filename, restored.Filename)
+	assert.Equal(t, old.ChecksumSHA256, restored.ChecksumSHA256)
+	assert.Equal(t, old.Size, restored.Size)
+	mockRepo.AssertNumberOfCalls(t, "Save", 2)
+}
+
+func Test_DeleteFile_MovesToTrash(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		TrashRetention: time.Hour,
+	})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	uploaded, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "hello.txt"}, strings.NewReader("hello world"))
+	require.NoError(t, err)
+	mockRepo.On("MoveToTrash", mock.Anything, uploaded.ID).Return(nil)
+
+	err = uc.DeleteFile(context.Background(), uploaded.ID)
+
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(storagePath, uploaded.Path))
+	assert.NoError(t, err, "trashed content must be kept until it is purged")
+	mockRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_DeleteFile_TrashesOnlyThatVersion(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		TrashRetention: time.Hour,
+	})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	first, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("one"))
+	require.NoError(t, err)
+	second, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("two"))
+	require.NoError(t, err)
+	mockRepo.On("MoveToTrash", mock.Anything, second.ID).Return(nil)
+	mockRepo.On("GetByFileName", mock.Anything, "", "notes.txt").Return(first, nil)
+
+	require.NoError(t, uc.DeleteFile(context.Background(), second.ID))
+
+	latest, err := uc.GetFileByName(context.Background(), "notes.txt")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, latest.ID)
+	mockRepo.AssertNotCalled(t, "MoveToTrash", mock.Anything, first.ID)
+	mockRepo.AssertNotCalled(t, "ListVersions", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_PurgeTrash_DeletesExpiredFiles(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		TrashRetention: time.Hour,
+	})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	trashed, err := uc.UploadFile(context.Background(), domain.FileUpload{Filename: "old.txt"}, strings.NewReader("old"))
+	require.NoError(t, err)
+
+	start := time.Now()
+	mockRepo.On("ListTrashedBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return !before.Before(start.Add(-time.Hour)) && before.Before(start)
+	}), purgeBatchSize).Return([]domain.File{*trashed}, nil)
+	mockRepo.On("MarkDeleted", mock.Anything, trashed.ID).Return(nil)
+	mockRepo.On("Delete", mock.Anything, trashed.ID).Return(nil)
+
+	purged, err := uc.PurgeTrash(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = os.Stat(filepath.Join(storagePath, trashed.Path))
+	assert.True(t, os.IsNotExist(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_PurgeFile_OnlyPurgesTrashedFiles(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{
+		TrashRetention: time.Hour,
+	})
+
+	mockRepo.On("GetTrashedByID", mock.Anything, "live-uuid").Return(nil, domain.ErrNotFound)
+
+	err := uc.PurgeFile(context.Background(), "live-uuid")
+
+	assert.True(t, errors.Is(err, domain.ErrNotFound))
+	mockRepo.AssertNotCalled(t, "MarkDeleted", mock.Anything, mock.Anything)
+}
+
+func Test_UploadFile_NormalizesTags(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+
+	file, err := uc.UploadFile(context.Background(), domain.FileUpload{
+		Filename:    "invoice.pdf",
+		Tags:        []string{" finance ", "", "q3", "finance"},
+		Description: "Quarterly invoice",
+		Labels:      map[string]string{"project": "alpha"},
+	}, strings.NewReader("%PDF-1.4"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance", "q3"}, file.Tags)
+	assert.Equal(t, "Quarterly invoice", file.Description)
+	assert.Equal(t, map[string]string{"project": "alpha"}, file.Labels)
+}
+
+func Test_UpdateFileMetadata_NormalizesReplacedTags(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	updated := &domain.File{ID: "file-uuid", Labels: map[string]string{"retention": "legal"}}
+	mockRepo.On("UpdateMetadata", mock.Anything, "file-uuid", domain.MetadataUpdate{
+		SetLabels:    map[string]string{"retention": "legal"},
+		RemoveLabels: []string{"project"},
+		Tags:         []string{"finance"},
+	}).Return(updated, nil)
+
+	file, err := uc.UpdateFileMetadata(context.Background(), "file-uuid", domain.MetadataUpdate{
+		SetLabels:    map[string]string{"retention": "legal"},
+		RemoveLabels: []string{"project"},
+		Tags:         []string{"finance ", "finance"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, file)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_SearchFiles_TrimsQueryAndClampsPageSize(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	expected := []domain.SearchResult{{File: domain.File{ID: "1"}, Rank: 0.5}}
+	mockRepo.On("Search", mock.Anything, "quarterly invoice", (*domain.Accessor)(nil), 1, 20).Return(expected, nil)
+
+	results, err := uc.SearchFiles(context.Background(), "  quarterly invoice ", 0, 1000)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, results)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_UploadFile_CleansPath(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+
+	file, err := uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "/projects//alpha/q3.pdf"}, strings.NewReader("report"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "projects/alpha/q3.pdf", file.Filename)
+
+	_, err = uc.UploadFile(context.Background(),
+		domain.FileUpload{Filename: "projects/../q3.pdf"}, strings.NewReader("report"))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	mockRepo.AssertNumberOfCalls(t, "Save", 1)
+}
+
+func Test_MoveFile_KeepsName(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	file := &domain.File{ID: "file-uuid", Filename: "projects/alpha/q3.pdf"}
+	moved := &domain.File{ID: "file-uuid", Filename: "archive/q3.pdf"}
+	mockRepo.On("GetByID", mock.Anything, "file-uuid").Return(file, nil).Once()
+	mockRepo.On("Rename", mock.Anything, "", "projects/alpha/q3.pdf", "archive/q3.pdf").Return(nil)
+	mockRepo.On("GetByID", mock.Anything, "file-uuid").Return(moved, nil).Once()
+
+	result, err := uc.MoveFile(context.Background(), "file-uuid", "/archive/")
+
+	require.NoError(t, err)
+	assert.Equal(t, "archive/q3.pdf", result.Filename)
+	mockRepo.AssertExpectations(t)
+}
+
+func Test_MoveFile_RejectsLongPath(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	file := &domain.File{ID: "file-uuid", Filename: "q3.pdf"}
+	mockRepo.On("GetByID", mock.Anything, "file-uuid").Return(file, nil)
+
+	folder := strings.Repeat("a", domain.MaxPathLength-len("q3.pdf"))
+	_, err := uc.MoveFile(context.Background(), "file-uuid", folder)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	mockRepo.AssertNotCalled(t, "Rename", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_RenameFile_StaysInFolder(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	file := &domain.File{ID: "file-uuid", Filename: "projects/alpha/q3.pdf"}
+	mockRepo.On("GetByID", mock.Anything, "file-uuid").Return(file, nil)
+	mockRepo.On("Rename", mock.Anything, "", "projects/alpha/q3.pdf", "projects/alpha/q4.pdf").Return(nil)
+
+	_, err := uc.RenameFile(context.Background(), "file-uuid", "q4.pdf")
+	require.NoError(t, err)
+
+	_, err = uc.RenameFile(context.Background(), "file-uuid", "other/q4.pdf")
+	assert.ErrorIs(t, err, domain.ErrInvalidPath)
+	mockRepo.AssertNumberOfCalls(t, "Rename", 1)
+}
+
+func Test_UploadFile_RecordsOwner(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	mockRepo.On("GetUsage", mock.Anything, "alice").Return(&domain.Usage{OwnerID: "alice"}, nil)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "alice"})
+
+	file, err := uc.UploadFile(ctx, domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("notes"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", file.OwnerID)
+
+	// Restoring a version keeps its owner rather than passing the file to
+	// whoever restored it.
+	mockRepo.On("GetByID", mock.Anything, file.ID).Return(file, nil)
+	mockRepo.On("GetPermission", mock.Anything, "alice", "notes.txt", domain.Accessor{ID: "bob"}).Return(domain.PermissionWrite, nil)
+	bobCtx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "bob"})
+
+	restored, err := uc.RestoreVersion(bobCtx, file.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice", restored.OwnerID)
+}
@@ -8,7 +8,45 @@ import (
 )
 
 type FileUseCase interface {
-	UploadFile(ctx context.Context, filename string, data io.Reader) (*domain.File, error)
-	DownLoadFile(ctx context.Context, filename string) (*domain.File, io.Reader, error)
-	ListFiles(ctx context.Context, page, pageSize int) (*domain.FileList, error)
+	UploadFile(ctx context.Context, upload domain.FileUpload, data io.Reader) (*domain.File, error)
+	DownLoadFile(ctx context.Context, fileID string, byteRange domain.ByteRange) (*domain.File, io.Reader, error)
+	DownloadStored(ctx context.Context, fileID string) (*domain.File, io.Reader, error)
+	ListFiles(ctx context.Context, filter domain.FileFilter, opts domain.ListOptions) (*domain.FileList, error)
+	GetFileByID(ctx context.Context, id string) (*domain.File, error)
+	GetFileByName(ctx context.Context, filename string) (*domain.File, error)
+	DeleteFile(ctx context.Context, fileID string) error
+	PurgePendingDeletes(ctx context.Context) (int, error)
+	PurgeUnreferencedBlobs(ctx context.Context) (int, error)
+	UpdateFileMetadata(ctx context.Context, fileID string, update domain.MetadataUpdate) (*domain.File, error)
+	SearchFiles(ctx context.Context, query string, page, pageSize int) ([]domain.SearchResult, error)
+	ListTrash(ctx context.Context, page, pageSize int) (*domain.FileList, error)
+	RestoreFile(ctx context.Context, fileID string) (*domain.File, error)
+	PurgeFile(ctx context.Context, fileID string) error
+	PurgeTrash(ctx context.Context) (int, error)
+	ListVersions(ctx context.Context, filename string) ([]domain.File, error)
+	RestoreVersion(ctx context.Context, fileID string) (*domain.File, error)
+	SetVersionRetention(ctx context.Context, filename string, keepVersions int) error
+	CreateFolder(ctx context.Context, path string) (*domain.Folder, error)
+	ListFolder(ctx context.Context, path string, after *domain.FolderCursor, pageSize int) (*domain.FolderListing, error)
+	MoveFile(ctx context.Context, fileID, folder string) (*domain.File, error)
+	RenameFile(ctx context.Context, fileID, name string) (*domain.File, error)
+	ShareFile(ctx context.Context, fileID string, grantee domain.Grantee, permission domain.Permission) (*domain.Grant, error)
+	UnshareFile(ctx context.Context, fileID string, grantee domain.Grantee) error
+	ListFileGrants(ctx context.Context, fileID string) ([]domain.Grant, error)
+	GetUsage(ctx context.Context) (*domain.Usage, error)
+	GetQuota(ctx context.Context, ownerID string) (*domain.Usage, error)
+	SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error)
+	CheckQuota(ctx context.Context, upload domain.FileUpload) error
+}
+
+type UploadSessionUseCase interface {
+	InitiateUpload(ctx context.Context, upload domain.FileUpload) (*domain.UploadSession, error)
+	UploadChunk(ctx context.Context, uploadID string, offset int64, data io.Reader) (*domain.UploadSession, error)
+	GetUploadStatus(ctx context.Context, uploadID string) (*domain.UploadSession, error)
+	CompleteUpload(ctx context.Context, uploadID string) (*domain.File, error)
+	ExpireSessions(ctx context.Context) (int, error)
+}
+
+type KeyRotationUseCase interface {
+	RotateKeys(ctx context.Context) (int, error)
 }
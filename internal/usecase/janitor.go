@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// CleanupTask removes leftovers of one kind and reports how many it removed.
+type CleanupTask struct {
+	Name string
+	Run  func(ctx context.Context) (int, error)
+}
+
+// Janitor periodically runs cleanup tasks such as expiring abandoned upload
+// sessions and finishing interrupted deletes.
+type Janitor struct {
+	interval time.Duration
+	tasks    []CleanupTask
+}
+
+func NewJanitor(interval time.Duration, tasks ...CleanupTask) *Janitor {
+	return &Janitor{
+		interval: interval,
+		tasks:    tasks,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, task := range j.tasks {
+				removed, err := task.Run(ctx)
+				if err != nil {
+					log.Printf("janitor: failed to clean up %s: %v", task.Name, err)
+					continue
+				}
+				if removed > 0 {
+					log.Printf("janitor: cleaned up %d %s", removed, task.Name)
+				}
+			}
+		}
+	}
+}
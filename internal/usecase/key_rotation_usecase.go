@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/encryption"
+	"github.com/grpc-file-storage-go/internal/repository"
+)
+
+const rotationBatchSize = 100
+
+type keyRotationUseCase struct {
+	repo    repository.DataKeyRepository
+	keyring encryption.Keyring
+}
+
+func NewKeyRotationUseCase(repo repository.DataKeyRepository, keyring encryption.Keyring) KeyRotationUseCase {
+	return &keyRotationUseCase{
+		repo:    repo,
+		keyring: keyring,
+	}
+}
+
+// RotateKeys re-wraps every data key that is not wrapped with the active
+// master key. Content is not touched, since the data keys stay the same.
+func (uc *keyRotationUseCase) RotateKeys(ctx context.Context) (int, error) {
+	rotated := 0
+	for {
+		keys, err := uc.repo.ListStale(ctx, uc.keyring.ActiveKeyID(), rotationBatchSize)
+		if err != nil {
+			return rotated, err
+		}
+		if len(keys) == 0 {
+			return rotated, nil
+		}
+
+		for _, key := range keys {
+			keyID, wrappedKey, err := uc.keyring.RewrapKey(key.KeyID, key.WrappedKey)
+			if err != nil {
+				return rotated, err
+			}
+
+			// A key that changed or disappeared meanwhile no longer needs
+			// rotating, or is picked up again by the next batch.
+			err = uc.repo.Rewrap(ctx, key, keyID, wrappedKey)
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return rotated, err
+			}
+			rotated++
+		}
+	}
+}
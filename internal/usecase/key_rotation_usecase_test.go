@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDataKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockDataKeyRepository) ListStale(ctx context.Context, activeKeyID string, limit int) ([]domain.WrappedDataKey, error) {
+	args := m.Called(ctx, activeKeyID, limit)
+
+	return args.Get(0).([]domain.WrappedDataKey), args.Error(1)
+}
+
+func (m *MockDataKeyRepository) Rewrap(ctx context.Context, old domain.WrappedDataKey, keyID string, wrappedKey []byte) error {
+	args := m.Called(ctx, old, keyID, wrappedKey)
+
+	return args.Error(0)
+}
+
+func Test_RotateKeys_RewrapsUnderActiveKey(t *testing.T) {
+	oldMaster := bytes.Repeat([]byte{1}, encryption.DataKeySize)
+	newMaster := bytes.Repeat([]byte{2}, encryption.DataKeySize)
+
+	oldKeyring, err := encryption.NewKeyring(map[string][]byte{"old": oldMaster}, "")
+	require.NoError(t, err)
+	_, dataKey, wrapped, err := oldKeyring.NewDataKey()
+	require.NoError(t, err)
+
+	keyring, err := encryption.NewKeyring(map[string][]byte{"old": oldMaster, "new": newMaster}, "new")
+	require.NoError(t, err)
+
+	stale := []domain.WrappedDataKey{
+		{FileID: "file-1", KeyID: "old", WrappedKey: wrapped},
+		{FileID: "file-2", KeyID: "old", WrappedKey: wrapped},
+	}
+	mockRepo := new(MockDataKeyRepository)
+	mockRepo.On("ListStale", mock.Anything, "new", rotationBatchSize).Return(stale, nil).Once()
+	mockRepo.On("ListStale", mock.Anything, "new", rotationBatchSize).Return([]domain.WrappedDataKey{}, nil).Once()
+	mockRepo.On("Rewrap", mock.Anything, stale[0], "new", mock.Anything).
+		Run(func(args mock.Arguments) {
+			unwrapped, err := keyring.UnwrapKey("new", args.Get(3).([]byte))
+			require.NoError(t, err)
+			assert.Equal(t, dataKey, unwrapped)
+		}).
+		Return(nil)
+	mockRepo.On("Rewrap", mock.Anything, stale[1], "new", mock.Anything).Return(domain.ErrNotFound)
+
+	rotated, err := NewKeyRotationUseCase(mockRepo, keyring).RotateKeys(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, rotated)
+	mockRepo.AssertExpectations(t)
+}
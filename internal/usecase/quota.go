@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+)
+
+// GetUsage returns what the caller stores and its quota.
+func (uc *fileUseCase) GetUsage(ctx context.Context) (*domain.Usage, error) {
+	accessor := accessorOf(ctx)
+	if accessor == nil {
+		return nil, fmt.Errorf("usage of unauthenticated callers: %w", domain.ErrNotFound)
+	}
+
+	return uc.repo.GetUsage(ctx, accessor.ID)
+}
+
+func (uc *fileUseCase) GetQuota(ctx context.Context, ownerID string) (*domain.Usage, error) {
+	if err := uc.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetUsage(ctx, ownerID)
+}
+
+func (uc *fileUseCase) SetQuota(ctx context.Context, ownerID string, quota domain.Quota) (*domain.Usage, error) {
+	if err := uc.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.SetQuota(ctx, ownerID, quota)
+}
+
+// requireAdmin checks that the caller may manage the quotas of everyone.
+// Unlike other calls, unauthenticated ones are refused: with
+// authentication disabled anyone could make them.
+func (uc *fileUseCase) requireAdmin(ctx context.Context) error {
+	accessor := accessorOf(ctx)
+	if accessor == nil {
+		return fmt.Errorf("%w: quotas can only be managed by admins", domain.ErrUnauthenticated)
+	}
+	if slices.Contains(uc.admins, accessor.ID) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is not an admin", domain.ErrPermissionDenied, accessor.ID)
+}
+
+// CheckQuota checks that one more file of the caller, of the declared size
+// if set, fits its quota. Resumable uploads check it when they start, so
+// that data which could not be kept is not even staged.
+func (uc *fileUseCase) CheckQuota(ctx context.Context, upload domain.FileUpload) error {
+	ownerID := ownerIDOf(ctx)
+	if ownerID == "" {
+		return nil
+	}
+
+	_, err := uc.checkQuota(ctx, ownerID, upload.DeclaredSize)
+
+	return err
+}
+
+// limitToQuota checks that one more file, of declaredSize bytes if
+// declared, fits the quota of ownerID. If the quota limits bytes, it
+// returns a reader of data that fails once the upload outgrows it. The
+// quota is enforced again when the file is saved; checking early spares
+// storing uploads that could not be kept.
+func (uc *fileUseCase) limitToQuota(ctx context.Context, ownerID string, declaredSize *int64, data io.Reader) (*quotaReader, error) {
+	usage, err := uc.checkQuota(ctx, ownerID, declaredSize)
+	if err != nil {
+		return nil, err
+	}
+	if usage.MaxBytes == 0 {
+		return nil, nil
+	}
+
+	return &quotaReader{r: data, ownerID: ownerID, remaining: usage.MaxBytes - usage.Bytes}, nil
+}
+
+func (uc *fileUseCase) checkQuota(ctx context.Context, ownerID string, declaredSize *int64) (*domain.Usage, error) {
+	usage, err := uc.repo.GetUsage(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int64(0)
+	if declaredSize != nil {
+		size = *declaredSize
+	}
+	if !usage.Allows(size, 1) {
+		return nil, quotaExceeded(ownerID)
+	}
+
+	return usage, nil
+}
+
+func quotaExceeded(ownerID string) error {
+	return fmt.Errorf("%w for %s", domain.ErrQuotaExceeded, ownerID)
+}
+
+// quotaReader fails once more than the remaining quota was read from r.
+type quotaReader struct {
+	r         io.Reader
+	ownerID   string
+	remaining int64
+	exceeded  bool
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.remaining -= int64(n)
+	if q.remaining < 0 {
+		q.exceeded = true
+		return 0, quotaExceeded(q.ownerID)
+	}
+
+	return n, err
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuotaTestUseCase(t *testing.T, usage *domain.Usage) (*MockFileRepository, FileUseCase, string) {
+	mockRepo := new(MockFileRepository)
+	storagePath := t.TempDir()
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(storagePath), FileUseCaseOptions{
+		Admins: []string{"root"},
+	})
+
+	mockRepo.On("GetVersionRetention", mock.Anything, mock.Anything, mock.Anything).Return(0, domain.ErrNotFound).Maybe()
+	mockRepo.On("GetUsage", mock.Anything, usage.OwnerID).Return(usage, nil).Maybe()
+
+	return mockRepo, uc, storagePath
+}
+
+func Test_UploadFile_DeclaredSizeOverQuota(t *testing.T) {
+	mockRepo, uc, _ := newQuotaTestUseCase(t, &domain.Usage{
+		OwnerID: "alice",
+		Bytes:   90,
+		Quota:   domain.Quota{MaxBytes: 100},
+	})
+	declaredSize := int64(11)
+
+	_, err := uc.UploadFile(asPrincipal("alice"), domain.FileUpload{
+		Filename:     "notes.txt",
+		DeclaredSize: &declaredSize,
+	}, strings.NewReader("hello world"))
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func Test_InitiateUpload_DeclaredSizeOverQuota(t *testing.T) {
+	_, files, storagePath := newQuotaTestUseCase(t, &domain.Usage{
+		OwnerID: "alice",
+		Bytes:   90,
+		Quota:   domain.Quota{MaxBytes: 100},
+	})
+	// The quota is checked before a session is stored.
+	sessions := NewUploadSessionUseCase(nil, files, storagePath, time.Hour)
+	declaredSize := int64(11)
+
+	_, err := sessions.InitiateUpload(asPrincipal("alice"), domain.FileUpload{
+		Filename:     "notes.txt",
+		DeclaredSize: &declaredSize,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	entries, err := os.ReadDir(storagePath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_UploadFile_StreamOverQuota(t *testing.T) {
+	mockRepo, uc, storagePath := newQuotaTestUseCase(t, &domain.Usage{
+		OwnerID: "alice",
+		Bytes:   90,
+		Quota:   domain.Quota{MaxBytes: 100},
+	})
+
+	_, err := uc.UploadFile(asPrincipal("alice"), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("hello world"))
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	entries, err := os.ReadDir(storagePath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_UploadFile_WithinQuota(t *testing.T) {
+	mockRepo, uc, _ := newQuotaTestUseCase(t, &domain.Usage{
+		OwnerID: "alice",
+		Bytes:   89,
+		Quota:   domain.Quota{MaxBytes: 100},
+	})
+	mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*domain.File")).Return(nil)
+
+	file, err := uc.UploadFile(asPrincipal("alice"), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("hello world"))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), file.Size)
+}
+
+func Test_UploadFile_FileCountQuota(t *testing.T) {
+	mockRepo, uc, _ := newQuotaTestUseCase(t, &domain.Usage{
+		OwnerID: "alice",
+		Files:   3,
+		Quota:   domain.Quota{MaxFiles: 3},
+	})
+
+	_, err := uc.UploadFile(asPrincipal("alice"), domain.FileUpload{Filename: "notes.txt"}, strings.NewReader("hi"))
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func Test_UploadFile_ChargesUploader(t *testing.T) {
+	mockRepo := new(MockFileRepository)
+	uc := NewFileUseCase(mockRepo, storage.NewLocalBlobStore(t.TempDir()), FileUseCaseOptions{})
+
+	// Alice owning a file with the same name makes no difference: bob's
+	// upload is a file of his own.
+	mockRepo.On("GetUsage", mock.Anything, "bob").Return(&domain.Usage{
+		OwnerID: "bob",
+		Files:   1,
+		Quota:   domain.Quota{MaxFiles: 1},
+	}, nil)
+
+	_, err := uc.UploadFile(asPrincipal("bob"), domain.FileUpload{Filename: "reports/q3.pdf"}, strings.NewReader("v2"))
+
+	assert.ErrorIs(t, err, domain.ErrQuotaExceeded)
+	mockRepo.AssertNotCalled(t, "GetUsage", mock.Anything, "alice")
+}
+
+func Test_SetQuota_AdminsOnly(t *testing.T) {
+	mockRepo, uc, _ := newQuotaTestUseCase(t, &domain.Usage{OwnerID: "alice"})
+	quota := domain.Quota{MaxBytes: 1 << 30}
+	mockRepo.On("SetQuota", mock.Anything, "alice", quota).Return(&domain.Usage{OwnerID: "alice", Quota: quota}, nil)
+
+	_, err := uc.SetQuota(asPrincipal("alice"), "alice", quota)
+	assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+	_, err = uc.SetQuota(context.Background(), "alice", quota)
+	assert.ErrorIs(t, err, domain.ErrUnauthenticated)
+
+	usage, err := uc.SetQuota(asPrincipal("root"), "alice", quota)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<30), usage.MaxBytes)
+	mockRepo.AssertNumberOfCalls(t, "SetQuota", 1)
+}
+
+func Test_GetUsage_OwnUsage(t *testing.T) {
+	_, uc, _ := newQuotaTestUseCase(t, &domain.Usage{OwnerID: "alice", Bytes: 42, Files: 2})
+
+	usage, err := uc.GetUsage(asPrincipal("alice"))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), usage.Bytes)
+	assert.Equal(t, int64(2), usage.Files)
+}
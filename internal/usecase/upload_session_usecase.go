@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grpc-file-storage-go/internal/domain"
+	"github.com/grpc-file-storage-go/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const stagingDir = ".staging"
+
+// uploadSessionUseCase stages resumable uploads on local disk and tracks
+// the streams writing them in memory, so all calls of a session must reach
+// the replica that started it. Deployments with several replicas have to
+// route them by upload id.
+type uploadSessionUseCase struct {
+	repo        repository.UploadSessionRepository
+	files       FileUseCase
+	stagingPath string
+	ttl         time.Duration
+
+	mu     sync.Mutex
+	active map[string]struct{}
+}
+
+// NewUploadSessionUseCase stages partial uploads under storagePath and hands
+// completed ones to files. Sessions without activity for ttl are expired.
+func NewUploadSessionUseCase(
+	repo repository.UploadSessionRepository,
+	files FileUseCase,
+	storagePath string,
+	ttl time.Duration,
+) UploadSessionUseCase {
+	return &uploadSessionUseCase{
+		repo:        repo,
+		files:       files,
+		stagingPath: filepath.Join(storagePath, stagingDir),
+		ttl:         ttl,
+		active:      make(map[string]struct{}),
+	}
+}
+
+func (uc *uploadSessionUseCase) InitiateUpload(ctx context.Context, upload domain.FileUpload) (*domain.UploadSession, error) {
+	// The path is checked now rather than once all data was sent.
+	filename, err := cleanFilename(upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+	upload.Filename = filename
+
+	// Staged data is only charged once the upload completes, so uploads
+	// that already cannot fit are refused before any of it is sent.
+	if err := uc.files.CheckQuota(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(uc.stagingPath, 0755); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &domain.UploadSession{
+		ID:         uuid.New().String(),
+		FileUpload: upload,
+		OwnerID:    ownerIDOf(ctx),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		ExpiresAt:  now.Add(uc.ttl),
+	}
+
+	file, err := os.Create(uc.stagedPath(session.ID))
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	if err := uc.repo.Create(ctx, session); err != nil {
+		os.Remove(uc.stagedPath(session.ID))
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (uc *uploadSessionUseCase) UploadChunk(ctx context.Context, uploadID string, offset int64, data io.Reader) (*domain.UploadSession, error) {
+	if err := uc.acquire(uploadID); err != nil {
+		return nil, err
+	}
+	defer uc.release(uploadID)
+
+	session, err := uc.getActive(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 || offset > session.BytesReceived {
+		return nil, domain.ErrInvalidOffset
+	}
+
+	file, err := os.OpenFile(uc.stagedPath(uploadID), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := file.Truncate(offset); err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Data past the declared size could never be completed, so it is not
+	// staged either.
+	reader := data
+	if session.DeclaredSize != nil {
+		reader = io.LimitReader(data, *session.DeclaredSize-offset)
+	}
+	written, copyErr := io.Copy(file, reader)
+	if copyErr == nil && session.DeclaredSize != nil && offset+written == *session.DeclaredSize {
+		if _, err := io.ReadFull(data, make([]byte, 1)); err == nil {
+			copyErr = domain.ErrUploadTooLarge
+		}
+	}
+
+	// Record progress even when the stream broke, so the client can resume
+	// from what actually reached the disk.
+	now := time.Now()
+	session.BytesReceived = offset + written
+	session.UpdatedAt = now
+	session.ExpiresAt = now.Add(uc.ttl)
+	if err := uc.repo.Update(context.WithoutCancel(ctx), session); err != nil {
+		return nil, err
+	}
+
+	if copyErr != nil {
+		return nil, copyErr
+	}
+
+	return session, nil
+}
+
+func (uc *uploadSessionUseCase) GetUploadStatus(ctx context.Context, uploadID string) (*domain.UploadSession, error) {
+	return uc.getActive(ctx, uploadID)
+}
+
+func (uc *uploadSessionUseCase) CompleteUpload(ctx context.Context, uploadID string) (*domain.File, error) {
+	if err := uc.acquire(uploadID); err != nil {
+		return nil, err
+	}
+	defer uc.release(uploadID)
+
+	session, err := uc.getActive(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.DeclaredSize != nil && *session.DeclaredSize != session.BytesReceived {
+		return nil, domain.ErrUploadIncomplete
+	}
+
+	staged, err := os.Open(uc.stagedPath(uploadID))
+	if err != nil {
+		return nil, err
+	}
+	defer staged.Close()
+
+	file, err := uc.files.UploadFile(ctx, session.FileUpload, io.LimitReader(staged, session.BytesReceived))
+	if err != nil {
+		return nil, err
+	}
+
+	uc.discard(ctx, uploadID)
+
+	return file, nil
+}
+
+func (uc *uploadSessionUseCase) ExpireSessions(ctx context.Context) (int, error) {
+	sessions, err := uc.repo.ListExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, session := range sessions {
+		if err := uc.acquire(session.ID); err != nil {
+			continue
+		}
+		uc.discard(ctx, session.ID)
+		uc.release(session.ID)
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (uc *uploadSessionUseCase) getActive(ctx context.Context, uploadID string) (*domain.UploadSession, error) {
+	session, err := uc.repo.GetByID(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, domain.ErrNotFound
+	}
+	// Other callers cannot tell the session exists.
+	if accessor := accessorOf(ctx); accessor != nil && session.OwnerID != "" && session.OwnerID != accessor.ID {
+		return nil, domain.ErrNotFound
+	}
+
+	return session, nil
+}
+
+func (uc *uploadSessionUseCase) discard(ctx context.Context, uploadID string) {
+	if err := os.Remove(uc.stagedPath(uploadID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("failed to remove staged upload %s: %v", uploadID, err)
+	}
+	if err := uc.repo.Delete(ctx, uploadID); err != nil {
+		log.Printf("failed to delete upload session %s: %v", uploadID, err)
+	}
+}
+
+func (uc *uploadSessionUseCase) acquire(uploadID string) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if _, busy := uc.active[uploadID]; busy {
+		return domain.ErrUploadSessionBusy
+	}
+	uc.active[uploadID] = struct{}{}
+
+	return nil
+}
+
+func (uc *uploadSessionUseCase) release(uploadID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	delete(uc.active, uploadID)
+}
+
+func (uc *uploadSessionUseCase) stagedPath(uploadID string) string {
+	return filepath.Join(uc.stagingPath, uploadID)
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS upload_sessions(
+    id VARCHAR (36) PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL,
+    declared_size BIGINT,
+    bytes_received BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at);
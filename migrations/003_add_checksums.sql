@@ -0,0 +1,5 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS checksum_sha256 VARCHAR(64);
+ALTER TABLE files ADD COLUMN IF NOT EXISTS checksum_crc32c BIGINT;
+
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS expected_sha256 VARCHAR(64);
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS expected_crc32c BIGINT;
@@ -0,0 +1,3 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS content_type VARCHAR(255) NOT NULL DEFAULT 'application/octet-stream';
+
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS content_type VARCHAR(255);
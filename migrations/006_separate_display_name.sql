@@ -0,0 +1,12 @@
+ALTER TABLE files RENAME COLUMN filename TO storage_name;
+DROP INDEX IF EXISTS idx_files_filename;
+
+ALTER TABLE files ADD COLUMN filename VARCHAR(255);
+UPDATE files SET filename = regexp_replace(
+    storage_name,
+    '_[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(\.[^.]*)?$',
+    '\1'
+);
+ALTER TABLE files ALTER COLUMN filename SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_files_filename ON files(filename, created_at DESC);
@@ -0,0 +1,3 @@
+-- Paths used to include the storage directory; blob store keys are
+-- relative to it and equal the storage name for existing files.
+UPDATE files SET path = storage_name;
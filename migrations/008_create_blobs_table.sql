@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS blobs(
+    sha256 VARCHAR(64) PRIMARY KEY,
+    path TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    ref_count INTEGER NOT NULL CHECK (ref_count >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE files ADD COLUMN IF NOT EXISTS blob_sha256 VARCHAR(64) REFERENCES blobs(sha256);
+
+CREATE INDEX IF NOT EXISTS idx_files_blob_sha256 ON files(blob_sha256);
@@ -0,0 +1,9 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS compression VARCHAR(16) NOT NULL DEFAULT 'none';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS stored_size BIGINT;
+UPDATE files SET stored_size = size WHERE stored_size IS NULL;
+ALTER TABLE files ALTER COLUMN stored_size SET NOT NULL;
+
+ALTER TABLE blobs ADD COLUMN IF NOT EXISTS compression VARCHAR(16) NOT NULL DEFAULT 'none';
+ALTER TABLE blobs ADD COLUMN IF NOT EXISTS stored_size BIGINT;
+UPDATE blobs SET stored_size = size WHERE stored_size IS NULL;
+ALTER TABLE blobs ALTER COLUMN stored_size SET NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS key_id VARCHAR(64);
+ALTER TABLE files ADD COLUMN IF NOT EXISTS wrapped_key BYTEA;
+
+ALTER TABLE blobs ADD COLUMN IF NOT EXISTS key_id VARCHAR(64);
+ALTER TABLE blobs ADD COLUMN IF NOT EXISTS wrapped_key BYTEA;
+
+CREATE INDEX IF NOT EXISTS idx_files_key_id ON files(key_id) WHERE key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_blobs_key_id ON blobs(key_id) WHERE key_id IS NOT NULL;
@@ -0,0 +1,18 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS version INTEGER;
+
+WITH numbered AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY filename ORDER BY created_at, id) AS version
+    FROM files
+)
+UPDATE files SET version = numbered.version
+FROM numbered
+WHERE files.id = numbered.id AND files.version IS NULL;
+
+ALTER TABLE files ALTER COLUMN version SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_filename_version ON files(filename, version);
+
+CREATE TABLE IF NOT EXISTS version_retention(
+    filename VARCHAR(255) PRIMARY KEY,
+    keep_versions INTEGER NOT NULL CHECK (keep_versions >= 0)
+);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_files_created_at_id ON files(created_at DESC, id DESC);
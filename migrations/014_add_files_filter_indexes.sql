@@ -0,0 +1,27 @@
+-- pg_trgm backs the substring index below. Creating it needs privileges
+-- that managed and least-privilege roles often lack; without it, substring
+-- filters still work but scan the files table.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+    RAISE NOTICE 'pg_trgm is not available, filename substring filters will not be indexed: %', SQLERRM;
+END
+$$;
+
+-- Prefix matches on filename, whatever the collation.
+CREATE INDEX IF NOT EXISTS idx_files_filename_pattern ON files(filename text_pattern_ops);
+-- Substring matches on filename.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS idx_files_filename_trgm ON files USING GIN (filename gin_trgm_ops);
+    END IF;
+END
+$$;
+CREATE INDEX IF NOT EXISTS idx_files_content_type ON files(content_type);
+
+-- Keyset pagination in every sort order.
+CREATE INDEX IF NOT EXISTS idx_files_updated_at_id ON files(updated_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_files_filename_id ON files(filename, id);
+CREATE INDEX IF NOT EXISTS idx_files_size_id ON files(size, id);
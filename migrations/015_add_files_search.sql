@@ -0,0 +1,28 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- The parser would keep names such as "invoice.pdf" as a single token, so
+-- filenames are split into words first. Matches in the filename rank above
+-- matches in tags, which rank above matches in the description.
+CREATE OR REPLACE FUNCTION files_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', regexp_replace(NEW.filename, '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+        setweight(to_tsvector('english', array_to_string(NEW.tags, ' ')), 'B') ||
+        setweight(to_tsvector('english', NEW.description), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS files_search_vector ON files;
+CREATE TRIGGER files_search_vector
+    BEFORE INSERT OR UPDATE OF filename, tags, description ON files
+    FOR EACH ROW EXECUTE FUNCTION files_search_vector_update();
+
+UPDATE files SET filename = filename WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_files_search_vector ON files USING GIN (search_vector);
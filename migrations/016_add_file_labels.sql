@@ -0,0 +1,5 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+
+-- Label filters are containment queries (labels @> '{"project": "alpha"}').
+CREATE INDEX IF NOT EXISTS idx_files_labels ON files USING GIN (labels jsonb_path_ops);
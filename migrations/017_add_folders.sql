@@ -0,0 +1,24 @@
+-- Filenames are paths such as "projects/alpha/q3.pdf". parent_path is the
+-- folder a file is in, "" at the root.
+ALTER TABLE files ADD COLUMN IF NOT EXISTS parent_path TEXT
+    GENERATED ALWAYS AS (regexp_replace(filename, '/?[^/]*$', '')) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_files_parent_path_filename ON files(parent_path, filename);
+
+CREATE TABLE IF NOT EXISTS folders(
+    path TEXT PRIMARY KEY,
+    parent_path TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_parent_path_path ON folders(parent_path, path);
+
+-- Existing files get the folders their names are in.
+WITH RECURSIVE ancestors(path) AS (
+    SELECT DISTINCT parent_path FROM files WHERE parent_path <> ''
+    UNION
+    SELECT regexp_replace(path, '/?[^/]*$', '') FROM ancestors WHERE path LIKE '%/%'
+)
+INSERT INTO folders (path, parent_path)
+SELECT path, regexp_replace(path, '/?[^/]*$', '') FROM ancestors
+ON CONFLICT (path) DO NOTHING;
@@ -0,0 +1,3 @@
+-- The principal that uploaded the file; NULL for files uploaded while
+-- authentication was disabled.
+ALTER TABLE files ADD COLUMN IF NOT EXISTS owner_id TEXT;
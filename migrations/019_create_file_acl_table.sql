@@ -0,0 +1,16 @@
+-- Grants share all versions of a file, identified by its filename, with a
+-- user or a group. The owner of a file needs no grant.
+CREATE TABLE IF NOT EXISTS file_acl(
+    filename VARCHAR(255) NOT NULL,
+    grantee_type TEXT NOT NULL CHECK (grantee_type IN ('user', 'group')),
+    grantee_id TEXT NOT NULL,
+    permission TEXT NOT NULL CHECK (permission IN ('read', 'write')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (filename, grantee_type, grantee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_acl_grantee ON file_acl(grantee_type, grantee_id);
+
+CREATE INDEX IF NOT EXISTS idx_files_owner_id ON files(owner_id);
+
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS owner_id TEXT;
@@ -0,0 +1,17 @@
+-- Quotas and usage per owner. Usage counts every version, including those
+-- in the trash, and is kept up to date as files are saved and deleted. A
+-- limit of zero is unlimited.
+CREATE TABLE IF NOT EXISTS quotas(
+    owner_id TEXT PRIMARY KEY,
+    max_bytes BIGINT NOT NULL DEFAULT 0 CHECK (max_bytes >= 0),
+    max_files BIGINT NOT NULL DEFAULT 0 CHECK (max_files >= 0),
+    used_bytes BIGINT NOT NULL DEFAULT 0,
+    used_files BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO quotas (owner_id, used_bytes, used_files)
+SELECT owner_id, SUM(size), COUNT(*)
+FROM files
+WHERE owner_id IS NOT NULL
+GROUP BY owner_id
+ON CONFLICT (owner_id) DO NOTHING;
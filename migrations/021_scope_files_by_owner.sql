@@ -0,0 +1,38 @@
+-- Filenames are scoped by owner: each owner has its own files, versions,
+-- retention settings and grants, and files uploaded while authentication
+-- was disabled form one more namespace. owner_key is owner_id with those
+-- files as '', so namespaces can be compared and indexed.
+ALTER TABLE files ADD COLUMN IF NOT EXISTS owner_key TEXT
+    GENERATED ALWAYS AS (COALESCE(owner_id, '')) STORED;
+
+DROP INDEX IF EXISTS idx_files_filename_version;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_owner_filename_version ON files(owner_key, filename, version);
+
+-- Retention settings and grants are keyed by the owner_key of the file,
+-- taken from the latest version of their filename.
+ALTER TABLE version_retention ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT '';
+UPDATE version_retention SET owner_id = latest_owners.owner_key
+FROM (
+    SELECT DISTINCT ON (filename) filename, owner_key
+    FROM files
+    WHERE NOT pending_delete
+    ORDER BY filename, version DESC
+) AS latest_owners
+WHERE latest_owners.filename = version_retention.filename;
+ALTER TABLE version_retention DROP CONSTRAINT IF EXISTS version_retention_pkey;
+ALTER TABLE version_retention ADD PRIMARY KEY (owner_id, filename);
+
+ALTER TABLE file_acl ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT '';
+UPDATE file_acl SET owner_id = latest_owners.owner_key
+FROM (
+    SELECT DISTINCT ON (filename) filename, owner_key
+    FROM files
+    WHERE NOT pending_delete
+    ORDER BY filename, version DESC
+) AS latest_owners
+WHERE latest_owners.filename = file_acl.filename;
+-- Only owners share files, so grants on unowned files grant nothing.
+DELETE FROM file_acl WHERE owner_id = '';
+ALTER TABLE file_acl ALTER COLUMN owner_id DROP DEFAULT;
+ALTER TABLE file_acl DROP CONSTRAINT IF EXISTS file_acl_pkey;
+ALTER TABLE file_acl ADD PRIMARY KEY (owner_id, filename, grantee_type, grantee_id);
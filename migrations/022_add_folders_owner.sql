@@ -0,0 +1,4 @@
+-- The principal that created the folder; NULL for folders created while
+-- authentication was disabled, or before folders had owners. Only the
+-- owner can create folders in an owned folder.
+ALTER TABLE folders ADD COLUMN IF NOT EXISTS owner_id TEXT;
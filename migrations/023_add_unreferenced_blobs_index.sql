@@ -0,0 +1,3 @@
+-- Blobs released by their last file are kept until the janitor has removed
+-- their content.
+CREATE INDEX IF NOT EXISTS idx_blobs_unreferenced ON blobs(sha256) WHERE ref_count = 0;